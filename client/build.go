@@ -13,47 +13,73 @@ const (
 	BuildProgressFlagAuto  BuildProgressFlag = "auto"
 	BuildProgressFlagPlain BuildProgressFlag = "plain"
 	BuildProgressFlagTTY   BuildProgressFlag = "tty"
+
+	// Emit BuildKit solve status as newline delimited JSON. Used by BuildWithProgress.
+	BuildProgressFlagRawJSON BuildProgressFlag = "rawjson"
 )
 
 // BuildOptions represents the command line options for the `docker compose build` command.
 //
+// Options that only apply to Docker Compose v1 are dropped when the client's ComposeVersion is v2 or later.
+// Options that require a newer version than the client's ComposeVersion return an UnsupportedFlagError.
+//
 // https://docs.docker.com/compose/reference/build/
 type BuildOptions struct {
 	// Set build-time variables for services.
 	BuildArgs map[string]string
 
-	// Compress the build context using gzip.
+	// Set builder to use. Requires docker compose 2.20.0 or later.
+	Builder string
+
+	// Check build configuration, without building. Requires docker compose 2.30.0 or later.
+	Check bool
+
+	// Compress the build context using gzip. Docker Compose v1 only.
 	Compress bool
 
-	// Always remove intermediate containers.
+	// Always remove intermediate containers. Docker Compose v1 only.
 	ForceRemove bool
 
-	// Set memory limit for the build container.
+	// Set memory limit for the build container. Not supported by BuildKit.
 	Memory string
 
 	// Do not use cache when building the image.
 	NoCache bool
 
-	// Do not remove intermediate containers after a successful build.
+	// Do not remove intermediate containers after a successful build. Docker Compose v1 only.
 	NoRemove bool
 
-	// Build images in parallel
+	// Build images in parallel. Docker Compose v1 only, v2 always builds in parallel.
 	Parallel bool
 
-	// Set type of progress output (`auto`, `plain`, `tty`).
+	// Print the equivalent bake file, without building. Requires docker compose 2.26.0 or later.
+	Print bool
+
+	// Set type of progress output (`auto`, `plain`, `tty`, `rawjson`).
 	Progress BuildProgressFlag
 
 	// Always attempt to pull a newer version of the image.
 	Pull bool
 
+	// Push service images after building. Requires docker compose 2.14.0 or later.
+	Push bool
+
 	// Don't print anything to `STDOUT`.
 	Quiet bool
 
+	// Set SSH authentications used when building service images (e.g. `default` to use the default SSH agent). Requires docker compose 2.4.0 or later.
+	//
+	// Build secrets and labels aren't command line options; they are declared under `build` in the Compose file.
+	SSH string
+
+	// Also build dependencies of the given services (transitively). Requires docker compose 2.22.0 or later.
+	WithDependencies bool
+
 	// Services to build
 	Services []string
 }
 
-func buildFlags(opts *BuildOptions) string {
+func buildFlags(opts *BuildOptions, compat compat) (string, error) {
 	flags := ""
 
 	if opts != nil {
@@ -70,11 +96,27 @@ func buildFlags(opts *BuildOptions) string {
 			flags = fmt.Sprintf("%s --build-arg %s=%s", flags, key, opts.BuildArgs[key])
 		}
 
-		if opts.Compress {
+		if opts.Builder != "" {
+			if err := compat.require("--builder", "2.20.0"); err != nil {
+				return "", err
+			}
+
+			flags = fmt.Sprintf("%s --builder %s", flags, opts.Builder)
+		}
+
+		if opts.Check {
+			if err := compat.require("--check", "2.30.0"); err != nil {
+				return "", err
+			}
+
+			flags = fmt.Sprintf("%s --check", flags)
+		}
+
+		if opts.Compress && !compat.v2() {
 			flags = fmt.Sprintf("%s --compress", flags)
 		}
 
-		if opts.ForceRemove {
+		if opts.ForceRemove && !compat.v2() {
 			flags = fmt.Sprintf("%s --force-rm", flags)
 		}
 
@@ -86,14 +128,22 @@ func buildFlags(opts *BuildOptions) string {
 			flags = fmt.Sprintf("%s --no-cache", flags)
 		}
 
-		if opts.NoRemove {
+		if opts.NoRemove && !compat.v2() {
 			flags = fmt.Sprintf("%s --no-rm", flags)
 		}
 
-		if opts.Parallel {
+		if opts.Parallel && !compat.v2() {
 			flags = fmt.Sprintf("%s --parallel", flags)
 		}
 
+		if opts.Print {
+			if err := compat.require("--print", "2.26.0"); err != nil {
+				return "", err
+			}
+
+			flags = fmt.Sprintf("%s --print", flags)
+		}
+
 		if opts.Progress != "" {
 			flags = fmt.Sprintf("%s --progress %s", flags, opts.Progress)
 		}
@@ -102,16 +152,40 @@ func buildFlags(opts *BuildOptions) string {
 			flags = fmt.Sprintf("%s --pull", flags)
 		}
 
+		if opts.Push {
+			if err := compat.require("--push", "2.14.0"); err != nil {
+				return "", err
+			}
+
+			flags = fmt.Sprintf("%s --push", flags)
+		}
+
 		if opts.Quiet {
 			flags = fmt.Sprintf("%s --quiet", flags)
 		}
 
+		if opts.SSH != "" {
+			if err := compat.require("--ssh", "2.4.0"); err != nil {
+				return "", err
+			}
+
+			flags = fmt.Sprintf("%s --ssh %s", flags, opts.SSH)
+		}
+
+		if opts.WithDependencies {
+			if err := compat.require("--with-dependencies", "2.22.0"); err != nil {
+				return "", err
+			}
+
+			flags = fmt.Sprintf("%s --with-dependencies", flags)
+		}
+
 		for _, service := range opts.Services {
 			flags = fmt.Sprintf("%s %s", flags, service)
 		}
 	}
 
-	return strings.TrimSpace(flags)
+	return strings.TrimSpace(flags), nil
 }
 
 // docker compose build
@@ -122,5 +196,99 @@ func buildFlags(opts *BuildOptions) string {
 //
 // https://docs.docker.com/compose/reference/build/
 func (c *ComposeClient) Build(opts *BuildOptions, w io.Writer, overrides ...*GlobalOptions) (<-chan error, error) {
-	return c.RunCommand("build", buildFlags(opts), w, nil, overrides...)
+	return processErrors(c.BuildProcess(opts, w, overrides...))
+}
+
+// docker compose build
+//
+// Build or rebuild services, returning a Process handle
+//
+// stdout is written to the given io.Writer
+//
+// https://docs.docker.com/compose/reference/build/
+func (c *ComposeClient) BuildProcess(opts *BuildOptions, w io.Writer, overrides ...*GlobalOptions) (*Process, error) {
+	flags, err := buildFlags(opts, c.compat())
+
+	if err != nil {
+		return nil, err
+	}
+
+	return c.RunProcess("build", flags, w, nil, overrides...)
+}
+
+// docker compose build
+//
+// Build or rebuild services, parsing the BuildKit progress output into typed BuildEvents.
+//
+// If no Progress option is set, `rawjson` is used. `plain` progress output is also supported.
+//
+// The events channel must be drained. It is closed once the command has completed, before the error channel emits.
+//
+// https://docs.docker.com/compose/reference/build/
+func (c *ComposeClient) BuildWithProgress(opts *BuildOptions, overrides ...*GlobalOptions) (<-chan BuildEvent, <-chan error, error) {
+	progressOpts := BuildOptions{}
+
+	if opts != nil {
+		progressOpts = *opts
+	}
+
+	if progressOpts.Progress == "" {
+		progressOpts.Progress = BuildProgressFlagRawJSON
+	}
+
+	return c.runBuildProgress(&progressOpts, nil, overrides...)
+}
+
+// runBuildProgress runs `docker compose build`, parsing stderr into BuildEvents. If raw is not nil, the unparsed output is also written to it.
+func (c *ComposeClient) runBuildProgress(opts *BuildOptions, raw io.Writer, overrides ...*GlobalOptions) (<-chan BuildEvent, <-chan error, error) {
+	flags, err := buildFlags(opts, c.compat())
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pr, pw := io.Pipe()
+
+	var stderr io.Writer = pw
+
+	if raw != nil {
+		stderr = io.MultiWriter(pw, raw)
+	}
+
+	ch, err := c.RunCommand("build", flags, nil, stderr, overrides...)
+
+	if err != nil {
+		pw.Close()
+		return nil, nil, err
+	}
+
+	events := make(chan BuildEvent)
+	parsed := make(chan struct{})
+
+	go func() {
+		defer close(parsed)
+		defer close(events)
+
+		NewBuildProgressParser().Parse(pr, func(event BuildEvent) {
+			events <- event
+		})
+
+		// Keep the pipe drained if parsing stopped early, so the command never blocks on a write
+		io.Copy(io.Discard, pr)
+	}()
+
+	errCh := make(chan error)
+
+	go func() {
+		defer close(errCh)
+
+		err := <-ch
+
+		pw.Close()
+		<-parsed
+
+		errCh <- err
+	}()
+
+	return events, errCh, nil
 }
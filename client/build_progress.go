@@ -0,0 +1,409 @@
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type BuildEventType string
+
+const (
+	// A build step has started.
+	BuildEventStepStarted BuildEventType = "started"
+
+	// A build step has completed successfully.
+	BuildEventStepCompleted BuildEventType = "completed"
+
+	// A build step was satisfied from the build cache.
+	BuildEventStepCached BuildEventType = "cached"
+
+	// A build step has failed.
+	BuildEventStepError BuildEventType = "error"
+
+	// A line of output produced by a build step.
+	BuildEventLog BuildEventType = "log"
+
+	// A digest was reported for a layer, manifest or image produced by the build.
+	BuildEventDigest BuildEventType = "digest"
+
+	// Any other progress update for a build step, e.g. `naming to docker.io/library/web`.
+	BuildEventStatus BuildEventType = "status"
+
+	// An error that is not attributed to a single build step, e.g. `failed to solve: ...`.
+	BuildEventError BuildEventType = "build-error"
+)
+
+// BuildEvent represents a single typed event parsed from BuildKit progress output.
+type BuildEvent struct {
+	Type BuildEventType
+
+	// The service being built, if it could be determined from the step name.
+	Service string
+
+	// The build stage, for multi-stage Dockerfiles (e.g. `builder`).
+	Stage string
+
+	// The step position within the Dockerfile (e.g. `2/5`).
+	Step string
+
+	// The step name with any `[service stage N/M]` prefix removed (e.g. `RUN apk add curl`).
+	Name string
+
+	// The BuildKit vertex identifier. This is the vertex digest for `rawjson` output, or the `#N` step number for `plain` output.
+	Vertex string
+
+	// The digest reported by the build, for BuildEventDigest events.
+	Digest string
+
+	// When the step started, if known.
+	Started *time.Time
+
+	// When the step completed, if known.
+	Completed *time.Time
+
+	// How long the step took, if known.
+	Duration time.Duration
+
+	// The log line, status or error message.
+	Message string
+}
+
+var (
+	buildStepNameRegexp  = regexp.MustCompile(`^\[([^\]]+)\]\s*(.*)$`)
+	buildStepRegexp      = regexp.MustCompile(`^\d+/\d+$`)
+	buildDigestRegexp    = regexp.MustCompile(`sha256:[0-9a-f]{64}`)
+	buildPlainLineRegexp = regexp.MustCompile(`^#(\d+) (.*)$`)
+	buildPlainDoneRegexp = regexp.MustCompile(`^DONE ([0-9.]+)s$`)
+	buildPlainLogRegexp  = regexp.MustCompile(`^[0-9]+\.[0-9]+ (.*)$`)
+	buildTargetRegexp    = regexp.MustCompile(`target ([^:\s]+):`)
+)
+
+type buildVertex struct {
+	service   string
+	stage     string
+	step      string
+	name      string
+	started   bool
+	completed bool
+	cached    bool
+	failed    bool
+}
+
+// BuildProgressParser converts BuildKit progress output into BuildEvents.
+//
+// Both `rawjson` and `plain` progress output is supported. The format is detected line by line, so output mixing the two is handled.
+type BuildProgressParser struct {
+	vertexes map[string]*buildVertex
+	statuses map[string]bool
+}
+
+// NewBuildProgressParser returns a new BuildProgressParser
+func NewBuildProgressParser() *BuildProgressParser {
+	return &BuildProgressParser{
+		vertexes: map[string]*buildVertex{},
+		statuses: map[string]bool{},
+	}
+}
+
+// Parse reads progress output from r until EOF, calling fn for each event.
+func (p *BuildProgressParser) Parse(r io.Reader, fn func(BuildEvent)) error {
+	reader := bufio.NewReader(r)
+
+	for {
+		line, err := reader.ReadBytes('\n')
+
+		if len(line) > 0 {
+			for _, event := range p.ParseLine(line) {
+				fn(event)
+			}
+		}
+
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// ParseLine parses a single line of progress output, returning any events it produced.
+func (p *BuildProgressParser) ParseLine(line []byte) []BuildEvent {
+	line = bytes.TrimSpace(line)
+
+	if len(line) == 0 {
+		return nil
+	}
+
+	if line[0] == '{' {
+		var status buildStatus
+
+		if err := json.Unmarshal(line, &status); err == nil {
+			return p.parseStatus(&status)
+		}
+	}
+
+	return p.parsePlain(string(line))
+}
+
+// buildStatus mirrors the BuildKit SolveStatus emitted by `--progress rawjson`
+type buildStatus struct {
+	Vertexes []struct {
+		Digest    string     `json:"digest"`
+		Name      string     `json:"name"`
+		Started   *time.Time `json:"started"`
+		Completed *time.Time `json:"completed"`
+		Cached    bool       `json:"cached"`
+		Error     string     `json:"error"`
+	} `json:"vertexes"`
+
+	Statuses []struct {
+		ID        string     `json:"id"`
+		Vertex    string     `json:"vertex"`
+		Completed *time.Time `json:"completed"`
+	} `json:"statuses"`
+
+	Logs []struct {
+		Vertex    string     `json:"vertex"`
+		Data      []byte     `json:"data"`
+		Timestamp *time.Time `json:"timestamp"`
+	} `json:"logs"`
+}
+
+func (p *BuildProgressParser) parseStatus(status *buildStatus) []BuildEvent {
+	var events []BuildEvent
+
+	for _, v := range status.Vertexes {
+		vertex := p.vertex(v.Digest, v.Name)
+
+		if v.Started != nil && !vertex.started {
+			vertex.started = true
+
+			event := vertex.event(BuildEventStepStarted, v.Digest)
+			event.Started = v.Started
+			events = append(events, event)
+		}
+
+		if v.Cached && !vertex.cached {
+			vertex.cached = true
+			events = append(events, vertex.event(BuildEventStepCached, v.Digest))
+		}
+
+		if v.Error != "" && !vertex.failed {
+			vertex.failed = true
+
+			event := vertex.event(BuildEventStepError, v.Digest)
+			event.Message = v.Error
+			event.Started = v.Started
+			event.Completed = v.Completed
+			events = append(events, event)
+		}
+
+		if v.Completed != nil && v.Error == "" && !vertex.completed {
+			vertex.completed = true
+
+			event := vertex.event(BuildEventStepCompleted, v.Digest)
+			event.Started = v.Started
+			event.Completed = v.Completed
+
+			if v.Started != nil {
+				event.Duration = v.Completed.Sub(*v.Started)
+			}
+
+			events = append(events, event)
+		}
+	}
+
+	for _, s := range status.Statuses {
+		if s.Completed == nil {
+			continue
+		}
+
+		events = append(events, p.statusEvents(s.Vertex, s.ID)...)
+	}
+
+	for _, l := range status.Logs {
+		vertex := p.vertex(l.Vertex, "")
+
+		for _, message := range strings.Split(strings.TrimRight(string(l.Data), "\n"), "\n") {
+			event := vertex.event(BuildEventLog, l.Vertex)
+			event.Message = message
+			event.Started = l.Timestamp
+			events = append(events, event)
+		}
+	}
+
+	return events
+}
+
+func (p *BuildProgressParser) parsePlain(line string) []BuildEvent {
+	match := buildPlainLineRegexp.FindStringSubmatch(line)
+
+	if match == nil {
+		if strings.Contains(line, "failed to solve") || strings.HasPrefix(line, "ERROR") || strings.HasPrefix(line, "error") {
+			event := BuildEvent{
+				Type:    BuildEventError,
+				Message: line,
+			}
+
+			if target := buildTargetRegexp.FindStringSubmatch(line); target != nil {
+				event.Service = target[1]
+			}
+
+			return []BuildEvent{event}
+		}
+
+		return nil
+	}
+
+	id := "#" + match[1]
+	rest := match[2]
+
+	if strings.HasPrefix(rest, "[") || !p.known(id) {
+		vertex := p.vertex(id, rest)
+
+		if vertex.started {
+			return nil
+		}
+
+		vertex.started = true
+
+		return []BuildEvent{vertex.event(BuildEventStepStarted, id)}
+	}
+
+	vertex := p.vertex(id, "")
+
+	switch {
+	case rest == "CACHED":
+		vertex.cached = true
+		return []BuildEvent{vertex.event(BuildEventStepCached, id)}
+
+	case buildPlainDoneRegexp.MatchString(rest):
+		vertex.completed = true
+
+		event := vertex.event(BuildEventStepCompleted, id)
+
+		if seconds, err := strconv.ParseFloat(buildPlainDoneRegexp.FindStringSubmatch(rest)[1], 64); err == nil {
+			event.Duration = time.Duration(seconds * float64(time.Second))
+		}
+
+		return []BuildEvent{event}
+
+	case strings.HasPrefix(rest, "ERROR"):
+		vertex.failed = true
+
+		event := vertex.event(BuildEventStepError, id)
+		event.Message = strings.TrimSpace(strings.TrimPrefix(strings.TrimPrefix(rest, "ERROR"), ":"))
+
+		return []BuildEvent{event}
+
+	case buildPlainLogRegexp.MatchString(rest):
+		event := vertex.event(BuildEventLog, id)
+		event.Message = buildPlainLogRegexp.FindStringSubmatch(rest)[1]
+
+		return []BuildEvent{event}
+
+	default:
+		return p.statusEvents(id, rest)
+	}
+}
+
+// statusEvents returns a digest event for each digest in the status text, or a single status event if there are none
+func (p *BuildProgressParser) statusEvents(id, text string) []BuildEvent {
+	if p.statuses[id+text] {
+		return nil
+	}
+
+	p.statuses[id+text] = true
+
+	vertex := p.vertex(id, "")
+	digests := buildDigestRegexp.FindAllString(text, -1)
+
+	if len(digests) == 0 {
+		event := vertex.event(BuildEventStatus, id)
+		event.Message = text
+
+		return []BuildEvent{event}
+	}
+
+	events := make([]BuildEvent, 0, len(digests))
+
+	for _, digest := range digests {
+		event := vertex.event(BuildEventDigest, id)
+		event.Digest = digest
+		event.Message = text
+		events = append(events, event)
+	}
+
+	return events
+}
+
+func (p *BuildProgressParser) known(id string) bool {
+	_, ok := p.vertexes[id]
+	return ok
+}
+
+// vertex returns the tracked state for the given vertex, creating it if necessary
+func (p *BuildProgressParser) vertex(id, name string) *buildVertex {
+	vertex, ok := p.vertexes[id]
+
+	if !ok {
+		vertex = &buildVertex{}
+		p.vertexes[id] = vertex
+	}
+
+	if name != "" && vertex.name == "" {
+		vertex.service, vertex.stage, vertex.step, vertex.name = parseBuildStepName(name)
+	}
+
+	return vertex
+}
+
+func (v *buildVertex) event(eventType BuildEventType, id string) BuildEvent {
+	return BuildEvent{
+		Type:    eventType,
+		Service: v.service,
+		Stage:   v.stage,
+		Step:    v.step,
+		Name:    v.name,
+		Vertex:  id,
+	}
+}
+
+// parseBuildStepName splits a step name like `[web builder 2/5] RUN make` into its parts
+func parseBuildStepName(name string) (service, stage, step, rest string) {
+	match := buildStepNameRegexp.FindStringSubmatch(name)
+
+	if match == nil {
+		return "", "", "", name
+	}
+
+	rest = match[2]
+	fields := strings.Fields(match[1])
+
+	if len(fields) > 0 && buildStepRegexp.MatchString(fields[len(fields)-1]) {
+		step = fields[len(fields)-1]
+		fields = fields[:len(fields)-1]
+	}
+
+	switch len(fields) {
+	case 0:
+	case 1:
+		// A lone `internal` is BuildKit's own bookkeeping rather than a service name
+		if fields[0] != "internal" {
+			service = fields[0]
+		}
+	default:
+		service = fields[0]
+		stage = strings.Join(fields[1:], " ")
+	}
+
+	return service, stage, step, rest
+}
@@ -0,0 +1,301 @@
+package client_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/harrim91/docker-compose-go/client"
+	"github.com/stretchr/testify/mock"
+)
+
+const buildProgressPlain string = `#1 [web internal] load build definition from Dockerfile
+#1 transferring dockerfile: 104B done
+#1 DONE 0.0s
+
+#5 [web 2/3] RUN apk add curl
+#5 CACHED
+
+#6 [web 3/3] RUN make
+#6 0.512 compiling
+#6 ERROR: process "/bin/sh -c make" did not complete successfully: exit code: 2
+------
+failed to solve: target web: process "/bin/sh -c make" did not complete successfully: exit code: 2
+`
+
+const buildProgressJSON string = `{"vertexes":[{"digest":"sha256:aaaa","name":"[api builder 1/2] FROM golang","started":"2022-04-01T10:00:00Z"}]}
+{"vertexes":[{"digest":"sha256:aaaa","name":"[api builder 1/2] FROM golang","started":"2022-04-01T10:00:00Z","completed":"2022-04-01T10:00:02Z"}],"logs":[{"vertex":"sha256:aaaa","stream":1,"data":"aGVsbG8Kd29ybGQK","timestamp":"2022-04-01T10:00:01Z"}]}
+{"vertexes":[{"digest":"sha256:bbbb","name":"[api 2/2] COPY . .","started":"2022-04-01T10:00:02Z","completed":"2022-04-01T10:00:02Z","cached":true}]}
+{"vertexes":[{"digest":"sha256:cccc","name":"exporting to image","started":"2022-04-01T10:00:03Z"}],"statuses":[{"id":"writing image sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef","vertex":"sha256:cccc","completed":"2022-04-01T10:00:04Z"}]}
+`
+
+type mockBuildProgressCmd struct {
+	mock.Mock
+	stdout io.Writer
+	stderr io.Writer
+}
+
+func (o *mockBuildProgressCmd) SetStdin(stdin io.Reader) {
+	o.Called(stdin)
+}
+
+func (o *mockBuildProgressCmd) SetStdout(stdout io.Writer) {
+	o.Called(stdout)
+	o.stdout = stdout
+}
+
+func (o *mockBuildProgressCmd) SetStderr(stderr io.Writer) {
+	o.Called(stderr)
+	o.stderr = stderr
+}
+
+func (o *mockBuildProgressCmd) Run(cmd string) (<-chan error, error) {
+	o.Called(cmd)
+
+	if strings.Contains(cmd, runErrFlag) {
+		return nil, errors.New(runErrFlag)
+	}
+
+	ch := make(chan error)
+
+	go func() {
+		if o.stderr != nil {
+			o.stderr.Write([]byte(buildProgressJSON))
+		}
+
+		if strings.Contains(cmd, processErrFlag) {
+			ch <- errors.New(processErrFlag)
+			return
+		}
+
+		ch <- nil
+	}()
+
+	return ch, nil
+}
+
+func parseBuildProgress(t *testing.T, output string) []client.BuildEvent {
+	var events []client.BuildEvent
+
+	err := client.NewBuildProgressParser().Parse(strings.NewReader(output), func(event client.BuildEvent) {
+		events = append(events, event)
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return events
+}
+
+func TestBuildProgressPlain(t *testing.T) {
+	events := parseBuildProgress(t, buildProgressPlain)
+
+	expected := []client.BuildEvent{
+		{Type: client.BuildEventStepStarted, Service: "web", Stage: "internal", Name: "load build definition from Dockerfile", Vertex: "#1"},
+		{Type: client.BuildEventStatus, Service: "web", Stage: "internal", Name: "load build definition from Dockerfile", Vertex: "#1", Message: "transferring dockerfile: 104B done"},
+		{Type: client.BuildEventStepCompleted, Service: "web", Stage: "internal", Name: "load build definition from Dockerfile", Vertex: "#1"},
+		{Type: client.BuildEventStepStarted, Service: "web", Step: "2/3", Name: "RUN apk add curl", Vertex: "#5"},
+		{Type: client.BuildEventStepCached, Service: "web", Step: "2/3", Name: "RUN apk add curl", Vertex: "#5"},
+		{Type: client.BuildEventStepStarted, Service: "web", Step: "3/3", Name: "RUN make", Vertex: "#6"},
+		{Type: client.BuildEventLog, Service: "web", Step: "3/3", Name: "RUN make", Vertex: "#6", Message: "compiling"},
+		{Type: client.BuildEventStepError, Service: "web", Step: "3/3", Name: "RUN make", Vertex: "#6", Message: `process "/bin/sh -c make" did not complete successfully: exit code: 2`},
+		{Type: client.BuildEventError, Service: "web", Message: `failed to solve: target web: process "/bin/sh -c make" did not complete successfully: exit code: 2`},
+	}
+
+	if len(events) != len(expected) {
+		t.Fatalf("expected %d events, got %d: %+v", len(expected), len(events), events)
+	}
+
+	for i := range expected {
+		if events[i] != expected[i] {
+			t.Errorf("event %d: expected %+v, got %+v", i, expected[i], events[i])
+		}
+	}
+}
+
+func TestBuildProgressPlainDuration(t *testing.T) {
+	events := parseBuildProgress(t, "#3 [web 1/1] FROM alpine\n#3 DONE 1.5s\n")
+
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %+v", events)
+	}
+
+	if events[1].Duration != 1500*time.Millisecond {
+		t.Errorf("expected duration 1.5s, got %s", events[1].Duration)
+	}
+}
+
+func TestBuildProgressJSON(t *testing.T) {
+	events := parseBuildProgress(t, buildProgressJSON)
+
+	types := []client.BuildEventType{
+		client.BuildEventStepStarted,
+		client.BuildEventStepCompleted,
+		client.BuildEventLog,
+		client.BuildEventLog,
+		client.BuildEventStepStarted,
+		client.BuildEventStepCached,
+		client.BuildEventStepCompleted,
+		client.BuildEventStepStarted,
+		client.BuildEventDigest,
+	}
+
+	if len(events) != len(types) {
+		t.Fatalf("expected %d events, got %d: %+v", len(types), len(events), events)
+	}
+
+	for i, eventType := range types {
+		if events[i].Type != eventType {
+			t.Errorf("event %d: expected type %s, got %s", i, eventType, events[i].Type)
+		}
+	}
+
+	if events[0].Service != "api" || events[0].Stage != "builder" || events[0].Step != "1/2" || events[0].Name != "FROM golang" {
+		t.Errorf("unexpected step details: %+v", events[0])
+	}
+
+	if events[1].Duration != 2*time.Second {
+		t.Errorf("expected duration 2s, got %s", events[1].Duration)
+	}
+
+	if events[2].Message != "hello" || events[3].Message != "world" || events[3].Service != "api" {
+		t.Errorf("unexpected log events: %+v, %+v", events[2], events[3])
+	}
+
+	digest := "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+	if events[8].Digest != digest {
+		t.Errorf("expected digest %s, got %s", digest, events[8].Digest)
+	}
+}
+
+func TestBuildProgressJSONError(t *testing.T) {
+	events := parseBuildProgress(t, `{"vertexes":[{"digest":"sha256:dddd","name":"[db 2/2] RUN false","started":"2022-04-01T10:00:00Z","completed":"2022-04-01T10:00:01Z","error":"exit code: 1"}]}`)
+
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %+v", events)
+	}
+
+	if events[1].Type != client.BuildEventStepError || events[1].Service != "db" || events[1].Message != "exit code: 1" {
+		t.Errorf("unexpected error event: %+v", events[1])
+	}
+}
+
+func TestBuildWithProgress(t *testing.T) {
+	cmd := &mockBuildProgressCmd{}
+
+	c := &client.ComposeClient{
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	cmd.On("SetStderr", mock.Anything)
+	cmd.On("Run", "docker compose build --progress rawjson web")
+
+	events, ch, err := c.BuildWithProgress(&client.BuildOptions{
+		Services: []string{"web"},
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	count := 0
+
+	for range events {
+		count++
+	}
+
+	if err := <-ch; err != nil {
+		t.Error(err)
+	}
+
+	cmd.AssertExpectations(t)
+
+	if count != 9 {
+		t.Errorf("expected 9 events, got %d", count)
+	}
+}
+
+func TestBuildWithProgressPlain(t *testing.T) {
+	cmd := &mockBuildProgressCmd{}
+
+	c := &client.ComposeClient{
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	cmd.On("SetStderr", mock.Anything)
+	cmd.On("Run", "docker compose build --progress plain")
+
+	events, ch, err := c.BuildWithProgress(&client.BuildOptions{
+		Progress: client.BuildProgressFlagPlain,
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for range events {
+	}
+
+	<-ch
+
+	cmd.AssertExpectations(t)
+}
+
+func TestBuildWithProgressProcessError(t *testing.T) {
+	cmd := &mockBuildProgressCmd{}
+
+	c := &client.ComposeClient{
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	cmd.On("SetStderr", mock.Anything)
+	cmd.On("Run", mock.Anything)
+
+	events, ch, err := c.BuildWithProgress(&client.BuildOptions{
+		Services: []string{processErrFlag},
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for range events {
+	}
+
+	err = <-ch
+
+	if err == nil || err.Error() != processErrFlag {
+		t.Errorf("expected error %s, got %v", processErrFlag, err)
+	}
+}
+
+func TestBuildWithProgressRunError(t *testing.T) {
+	cmd := &mockBuildProgressCmd{}
+
+	c := &client.ComposeClient{
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	cmd.On("SetStderr", mock.Anything)
+	cmd.On("Run", mock.Anything)
+
+	_, _, err := c.BuildWithProgress(&client.BuildOptions{
+		Services: []string{runErrFlag},
+	})
+
+	if err == nil || err.Error() != runErrFlag {
+		t.Errorf("expected error %s, got %v", runErrFlag, err)
+	}
+}
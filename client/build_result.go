@@ -0,0 +1,244 @@
+package client
+
+import (
+	"encoding/json"
+	"io"
+	"regexp"
+	"strings"
+)
+
+var buildPushRegexp = regexp.MustCompile(`^pushing manifest for (\S+)@(sha256:[0-9a-f]{64})`)
+
+// BuildImage describes an image produced by `docker compose build`
+type BuildImage struct {
+	// The service the image was built for, if it could be determined.
+	Service string
+
+	// The name the image was tagged with (e.g. `docker.io/library/myproject-web:latest`).
+	Reference string
+
+	// The local image ID.
+	ImageID string
+
+	// The manifest digest. For pushed images this is the digest in the registry.
+	Digest string
+
+	// True if the image was pushed to a registry.
+	Pushed bool
+}
+
+// BuildResult is emitted once a BuildWithResult command has completed
+type BuildResult struct {
+	// Images produced by the build, keyed by service name.
+	// Images that could not be attributed to a service are keyed by their reference.
+	Images map[string]*BuildImage
+
+	// The error returned by the build command, if any
+	Err error
+}
+
+// BuildResultCollector gathers image metadata from the BuildEvents produced by a build.
+type BuildResultCollector struct {
+	images map[string]*BuildImage
+	order  []string
+}
+
+// NewBuildResultCollector returns a new BuildResultCollector
+func NewBuildResultCollector() *BuildResultCollector {
+	return &BuildResultCollector{
+		images: map[string]*BuildImage{},
+	}
+}
+
+// Add records any image metadata contained in the given event.
+func (r *BuildResultCollector) Add(event BuildEvent) {
+	message := strings.TrimSuffix(strings.TrimSpace(event.Message), " done")
+
+	switch {
+	case event.Type == BuildEventStatus && strings.HasPrefix(message, "naming to "):
+		image := r.image(event)
+
+		if image.Reference == "" {
+			image.Reference = strings.TrimPrefix(message, "naming to ")
+		}
+
+	case event.Type != BuildEventDigest:
+		return
+
+	case strings.HasPrefix(message, "writing image "), strings.HasPrefix(message, "exporting config "):
+		r.image(event).ImageID = event.Digest
+
+	case strings.HasPrefix(message, "exporting manifest list "):
+		r.image(event).Digest = event.Digest
+
+	case strings.HasPrefix(message, "exporting manifest "):
+		image := r.image(event)
+
+		if image.Digest == "" {
+			image.Digest = event.Digest
+		}
+
+	case buildPushRegexp.MatchString(message):
+		match := buildPushRegexp.FindStringSubmatch(message)
+		image := r.image(event)
+
+		image.Digest = match[2]
+		image.Pushed = true
+
+		if image.Reference == "" {
+			image.Reference = match[1]
+		}
+	}
+}
+
+// Images returns the images seen so far, in the order they were first reported.
+func (r *BuildResultCollector) Images() []*BuildImage {
+	images := make([]*BuildImage, 0, len(r.order))
+
+	for _, vertex := range r.order {
+		images = append(images, r.images[vertex])
+	}
+
+	return images
+}
+
+// image returns the image exported by the event's vertex, creating it if necessary
+func (r *BuildResultCollector) image(event BuildEvent) *BuildImage {
+	image, ok := r.images[event.Vertex]
+
+	if !ok {
+		image = &BuildImage{}
+		r.images[event.Vertex] = image
+		r.order = append(r.order, event.Vertex)
+	}
+
+	if image.Service == "" {
+		image.Service = event.Service
+	}
+
+	return image
+}
+
+// normalizeImageReference strips the default registry, namespace and tag from an image reference
+func normalizeImageReference(ref string) string {
+	ref = strings.TrimPrefix(ref, "docker.io/")
+	ref = strings.TrimPrefix(ref, "library/")
+
+	if !strings.Contains(ref, "@") && strings.LastIndex(ref, ":") <= strings.LastIndex(ref, "/") {
+		ref = ref + ":latest"
+	}
+
+	return ref
+}
+
+// resolveBuildImageServices attributes images to services using the image names from the resolved Compose config
+func (c *ComposeClient) resolveBuildImageServices(images []*BuildImage, overrides ...*GlobalOptions) {
+	res, err := c.RunQuery("config", configFlags(nil), overrides...)
+
+	if err != nil {
+		return
+	}
+
+	var config struct {
+		Name     string `json:"name"`
+		Services map[string]struct {
+			Image string `json:"image"`
+		} `json:"services"`
+	}
+
+	if err := json.Unmarshal(res, &config); err != nil {
+		return
+	}
+
+	refs := map[string]string{}
+
+	for service, s := range config.Services {
+		image := s.Image
+
+		// Compose names images it builds `<project>-<service>` unless the service sets an image
+		if image == "" {
+			image = config.Name + "-" + service
+		}
+
+		refs[normalizeImageReference(image)] = service
+	}
+
+	for _, image := range images {
+		if image.Service == "" {
+			image.Service = refs[normalizeImageReference(image.Reference)]
+		}
+	}
+}
+
+// docker compose build
+//
+// Build or rebuild services, returning metadata about the images that were produced.
+//
+// The image reference, ID and digest of each service is collected from the BuildKit progress output.
+// Images that can't be attributed to a service from the progress output alone are matched against the resolved Compose config.
+//
+// If no Progress option is set, `plain` is used. The raw progress output is written to the given io.Writer.
+//
+// https://docs.docker.com/compose/reference/build/
+func (c *ComposeClient) BuildWithResult(opts *BuildOptions, w io.Writer, overrides ...*GlobalOptions) (<-chan BuildResult, error) {
+	resultOpts := BuildOptions{}
+
+	if opts != nil {
+		resultOpts = *opts
+	}
+
+	if resultOpts.Progress == "" {
+		resultOpts.Progress = BuildProgressFlagPlain
+	}
+
+	// Images may be attributed to services by a separate command, which would otherwise find an inline file's Reader consumed
+	if err := c.bufferInlineFiles(overrides...); err != nil {
+		return nil, err
+	}
+
+	events, ch, err := c.runBuildProgress(&resultOpts, w, overrides...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	resultCh := make(chan BuildResult)
+
+	go func() {
+		defer close(resultCh)
+
+		collector := NewBuildResultCollector()
+
+		for event := range events {
+			collector.Add(event)
+		}
+
+		result := BuildResult{
+			Images: map[string]*BuildImage{},
+			Err:    <-ch,
+		}
+
+		images := collector.Images()
+
+		for _, image := range images {
+			if image.Service == "" {
+				c.resolveBuildImageServices(images, overrides...)
+				break
+			}
+		}
+
+		for _, image := range images {
+			key := image.Service
+
+			if key == "" {
+				key = image.Reference
+			}
+
+			result.Images[key] = image
+		}
+
+		resultCh <- result
+	}()
+
+	return resultCh, nil
+}
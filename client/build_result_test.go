@@ -0,0 +1,209 @@
+package client_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/harrim91/docker-compose-go/client"
+	"github.com/stretchr/testify/mock"
+)
+
+const (
+	webImageID   string = "sha256:1111111111111111111111111111111111111111111111111111111111111111"
+	apiImageID   string = "sha256:2222222222222222222222222222222222222222222222222222222222222222"
+	apiDigest    string = "sha256:3333333333333333333333333333333333333333333333333333333333333333"
+	buildConfig  string = `{"name": "myproject", "services": {"web": {}, "api": {"image": "registry.example.com/api:v1"}}}`
+	buildOutputs string = `#1 [web 1/1] FROM alpine
+#1 DONE 0.1s
+
+#2 exporting to image
+#2 writing image ` + webImageID + ` done
+#2 naming to docker.io/library/myproject-web done
+#2 DONE 0.2s
+
+#3 [api] exporting to image
+#3 exporting config ` + apiImageID + ` done
+#3 naming to registry.example.com/api:v1 done
+#3 pushing manifest for registry.example.com/api:v1@` + apiDigest + ` done
+#3 DONE 1.0s
+`
+)
+
+type mockBuildResultCmd struct {
+	mock.Mock
+	stdout io.Writer
+	stderr io.Writer
+}
+
+func (o *mockBuildResultCmd) SetStdin(stdin io.Reader) {
+	o.Called(stdin)
+}
+
+func (o *mockBuildResultCmd) SetStdout(stdout io.Writer) {
+	o.Called(stdout)
+	o.stdout = stdout
+}
+
+func (o *mockBuildResultCmd) SetStderr(stderr io.Writer) {
+	o.Called(stderr)
+	o.stderr = stderr
+}
+
+func (o *mockBuildResultCmd) Run(cmd string) (<-chan error, error) {
+	o.Called(cmd)
+
+	if strings.Contains(cmd, runErrFlag) {
+		return nil, errors.New(runErrFlag)
+	}
+
+	ch := make(chan error)
+
+	go func() {
+		if strings.Contains(cmd, " config ") {
+			o.stdout.Write([]byte(buildConfig))
+		} else if o.stderr != nil {
+			o.stderr.Write([]byte(buildOutputs))
+		}
+
+		if strings.Contains(cmd, processErrFlag) {
+			ch <- errors.New(processErrFlag)
+			return
+		}
+
+		ch <- nil
+	}()
+
+	return ch, nil
+}
+
+func TestBuildResultCollector(t *testing.T) {
+	collector := client.NewBuildResultCollector()
+
+	client.NewBuildProgressParser().Parse(strings.NewReader(buildOutputs), collector.Add)
+
+	images := collector.Images()
+
+	if len(images) != 2 {
+		t.Fatalf("expected 2 images, got %d", len(images))
+	}
+
+	web := client.BuildImage{
+		Reference: "docker.io/library/myproject-web",
+		ImageID:   webImageID,
+	}
+
+	if *images[0] != web {
+		t.Errorf("expected %+v, got %+v", web, *images[0])
+	}
+
+	api := client.BuildImage{
+		Service:   "api",
+		Reference: "registry.example.com/api:v1",
+		ImageID:   apiImageID,
+		Digest:    apiDigest,
+		Pushed:    true,
+	}
+
+	if *images[1] != api {
+		t.Errorf("expected %+v, got %+v", api, *images[1])
+	}
+}
+
+func TestBuildWithResult(t *testing.T) {
+	cmd := &mockBuildResultCmd{}
+
+	c := &client.ComposeClient{
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	var buff bytes.Buffer
+
+	cmd.On("SetStderr", mock.Anything)
+	cmd.On("SetStdout", mock.Anything)
+	cmd.On("Run", "docker compose build --progress plain")
+	cmd.On("Run", "docker compose config --format json")
+
+	ch, err := c.BuildWithResult(nil, &buff)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := <-ch
+
+	if result.Err != nil {
+		t.Error(result.Err)
+	}
+
+	cmd.AssertExpectations(t)
+
+	if buff.String() != buildOutputs {
+		t.Errorf("expected raw output to be written to the writer, got: %s", buff.String())
+	}
+
+	if len(result.Images) != 2 {
+		t.Fatalf("expected 2 images, got %+v", result.Images)
+	}
+
+	if image := result.Images["web"]; image == nil || image.ImageID != webImageID || image.Service != "web" {
+		t.Errorf("expected web image %s, got %+v", webImageID, image)
+	}
+
+	if image := result.Images["api"]; image == nil || image.Digest != apiDigest {
+		t.Errorf("expected api digest %s, got %+v", apiDigest, image)
+	}
+}
+
+func TestBuildWithResultProcessError(t *testing.T) {
+	cmd := &mockBuildResultCmd{}
+
+	c := &client.ComposeClient{
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	cmd.On("SetStderr", mock.Anything)
+	cmd.On("SetStdout", mock.Anything)
+	cmd.On("Run", mock.Anything)
+
+	ch, err := c.BuildWithResult(&client.BuildOptions{
+		Services: []string{processErrFlag},
+	}, nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := <-ch
+
+	if result.Err == nil || result.Err.Error() != processErrFlag {
+		t.Errorf("expected error %s, got %v", processErrFlag, result.Err)
+	}
+}
+
+func TestBuildWithResultRunError(t *testing.T) {
+	cmd := &mockBuildResultCmd{}
+
+	c := &client.ComposeClient{
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	cmd.On("SetStderr", mock.Anything)
+	cmd.On("Run", mock.Anything)
+
+	_, err := c.BuildWithResult(&client.BuildOptions{
+		Services: []string{runErrFlag},
+	}, nil)
+
+	if err == nil || err.Error() != runErrFlag {
+		t.Errorf("expected error %s, got %v", runErrFlag, err)
+	}
+}
@@ -2,9 +2,11 @@ package client_test
 
 import (
 	"bytes"
+	"errors"
 	"testing"
 
 	"github.com/harrim91/docker-compose-go/client"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestBuildCommand(t *testing.T) {
@@ -280,3 +282,205 @@ func TestBuildCommandIOWriter(t *testing.T) {
 
 	cmd.AssertExpectations(t)
 }
+
+func TestBuildCommandBuilder(t *testing.T) {
+	cmd := &MockCmd{}
+
+	c := &client.ComposeClient{
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	cmd.On("Run", "docker compose build --builder my-builder")
+
+	c.Build(&client.BuildOptions{
+		Builder: "my-builder",
+	}, nil)
+
+	cmd.AssertExpectations(t)
+}
+
+func TestBuildCommandCheck(t *testing.T) {
+	cmd := &MockCmd{}
+
+	c := &client.ComposeClient{
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	cmd.On("Run", "docker compose build --check")
+
+	c.Build(&client.BuildOptions{
+		Check: true,
+	}, nil)
+
+	cmd.AssertExpectations(t)
+}
+
+func TestBuildCommandPrint(t *testing.T) {
+	cmd := &MockCmd{}
+
+	c := &client.ComposeClient{
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	cmd.On("Run", "docker compose build --print")
+
+	c.Build(&client.BuildOptions{
+		Print: true,
+	}, nil)
+
+	cmd.AssertExpectations(t)
+}
+
+func TestBuildCommandPush(t *testing.T) {
+	cmd := &MockCmd{}
+
+	c := &client.ComposeClient{
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	cmd.On("Run", "docker compose build --push")
+
+	c.Build(&client.BuildOptions{
+		Push: true,
+	}, nil)
+
+	cmd.AssertExpectations(t)
+}
+
+func TestBuildCommandSSH(t *testing.T) {
+	cmd := &MockCmd{}
+
+	c := &client.ComposeClient{
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	cmd.On("Run", "docker compose build --ssh default")
+
+	c.Build(&client.BuildOptions{
+		SSH: "default",
+	}, nil)
+
+	cmd.AssertExpectations(t)
+}
+
+func TestBuildCommandWithDependencies(t *testing.T) {
+	cmd := &MockCmd{}
+
+	c := &client.ComposeClient{
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	cmd.On("Run", "docker compose build --with-dependencies")
+
+	c.Build(&client.BuildOptions{
+		WithDependencies: true,
+	}, nil)
+
+	cmd.AssertExpectations(t)
+}
+
+func TestBuildCommandV1FlagsDroppedOnV2(t *testing.T) {
+	cmd := &MockCmd{}
+
+	c := &client.ComposeClient{
+		ComposeVersion: "v2.24.0",
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	cmd.On("Run", "docker compose build --no-cache")
+
+	c.Build(&client.BuildOptions{
+		Compress:    true,
+		ForceRemove: true,
+		NoCache:     true,
+		NoRemove:    true,
+		Parallel:    true,
+	}, nil)
+
+	cmd.AssertExpectations(t)
+}
+
+func TestBuildCommandV1FlagsKeptOnV1(t *testing.T) {
+	cmd := &MockCmd{}
+
+	c := &client.ComposeClient{
+		ComposeVersion: "1.29.2",
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	cmd.On("Run", "docker compose build --compress --parallel")
+
+	c.Build(&client.BuildOptions{
+		Compress: true,
+		Parallel: true,
+	}, nil)
+
+	cmd.AssertExpectations(t)
+}
+
+func TestBuildCommandUnsupportedFlag(t *testing.T) {
+	cmd := &MockCmd{}
+
+	c := &client.ComposeClient{
+		ComposeVersion: "v2.10.0",
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	_, err := c.Build(&client.BuildOptions{
+		Push: true,
+	}, nil)
+
+	var unsupported *client.UnsupportedFlagError
+
+	if !errors.As(err, &unsupported) {
+		t.Fatalf("expected UnsupportedFlagError, got %v", err)
+	}
+
+	if unsupported.Flag != "--push" || unsupported.MinVersion != "2.14.0" || unsupported.Version != "2.10.0" {
+		t.Errorf("unexpected error: %+v", unsupported)
+	}
+
+	cmd.AssertNotCalled(t, "Run", mock.Anything)
+}
+
+func TestBuildCommandSupportedFlag(t *testing.T) {
+	cmd := &MockCmd{}
+
+	c := &client.ComposeClient{
+		ComposeVersion: "2.24.0-desktop.1",
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	cmd.On("Run", "docker compose build --push --with-dependencies")
+
+	_, err := c.Build(&client.BuildOptions{
+		Push:             true,
+		WithDependencies: true,
+	}, nil)
+
+	if err != nil {
+		t.Error(err)
+	}
+
+	cmd.AssertExpectations(t)
+}
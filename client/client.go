@@ -1,11 +1,12 @@
 package client
 
 import (
-	"bufio"
 	"bytes"
 	"fmt"
 	"io"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/harrim91/docker-compose-go/cmd"
 )
@@ -24,204 +25,259 @@ func New(opts *GlobalOptions) *ComposeClient {
 type ComposeClient struct {
 	GlobalOptions *GlobalOptions
 	NewCmd        func() Cmd
+
+	// The version of Docker Compose in use (e.g. `v2.24.0`), normally set with `DetectVersion`.
+	// When set, options are translated to the flags that version supports. When empty, options are passed through as-is.
+	ComposeVersion string
+
+	// Tracks commands and projects for teardown, when enabled with `EnableShutdownHook`
+	shutdown   *ShutdownHook
+	shutdownMu sync.Mutex
 }
 
 type Cmd interface {
+	SetStdin(stdin io.Reader)
 	SetStderr(stderr io.Writer)
 	SetStdout(stdout io.Writer)
 	Run(command string) (<-chan error, error)
 }
 
+type ANSIFlag string
+
+const (
+	ANSIFlagNever  ANSIFlag = "never"
+	ANSIFlagAlways ANSIFlag = "always"
+	ANSIFlagAuto   ANSIFlag = "auto"
+)
+
+type ProgressFlag string
+
+const (
+	ProgressFlagAuto  ProgressFlag = "auto"
+	ProgressFlagTTY   ProgressFlag = "tty"
+	ProgressFlagPlain ProgressFlag = "plain"
+	ProgressFlagJSON  ProgressFlag = "json"
+	ProgressFlagQuiet ProgressFlag = "quiet"
+)
+
 // GlobalOptions represents the global configuration options for the ComposeClient
 //
+// When the client's ComposeVersion is v2 or later, v1-era options are translated to their modern equivalents:
+// NoANSI becomes `--ansi never`, and Verbose, Context, Host and the TLS options are passed to the docker CLI (e.g. `docker --host ... compose ...`).
+// Options that require a newer version than the client's ComposeVersion return an UnsupportedFlagError.
+//
 // https://docs.docker.com/compose/reference/
 type GlobalOptions struct {
 	// Specify alternate compose file(s) (default: docker-compose.yml)
 	Files []string
 
+	// Compose files supplied as content rather than paths, used after Files. See ComposeFile.
+	InlineFiles []ComposeFile
+
+	// How InlineFiles are passed to docker compose (default: stdin for a single file if the command has no other input, temporary files otherwise)
+	InlineFileMode InlineFileMode
+
 	// Specify an alternate project name (default: directory name)
 	ProjectName string
 
 	// Specify a profile to enable
 	Profiles []string
 
-	// Show more output
+	// Specify alternate environment file(s)
+	EnvFiles []string
+
+	// Control max parallelism, -1 for unlimited. Requires docker compose 2.14.0 or later.
+	Parallel *int
+
+	// Control when to print ANSI control characters (`never`, `always`, `auto`)
+	ANSI ANSIFlag
+
+	// Set type of progress output (`auto`, `tty`, `plain`, `json`, `quiet`). Requires docker compose 2.20.0 or later.
+	Progress ProgressFlag
+
+	// Execute command in dry run mode. Requires docker compose 2.14.0 or later.
+	DryRun *bool
+
+	// Show more output. Translated to `docker --debug` on v2.
 	Verbose *bool
 
-	// Do not print ANSI control characters
+	// Do not print ANSI control characters. Deprecated, use ANSI. Translated to `--ansi never` on v2.
 	NoANSI *bool
 
-	// Daemon socket to connect to
+	// Name of the Docker context to use. Overrides Host, the TLS options and DOCKER_HOST, which aren't passed when a context
+	// is set. Passed to the docker CLI on v2.
+	//
+	// Use DockerContextStore to resolve a context's connection settings without the docker CLI.
+	Context string
+
+	// Daemon socket to connect to. Passed to the docker CLI on v2.
 	Host string
 
-	// Use TLS; implied by TLSVerify
+	// Use TLS; implied by TLSVerify. Passed to the docker CLI on v2.
 	TLS *bool
 
-	// Trust certs signed only by this CA
+	// Trust certs signed only by this CA. Passed to the docker CLI on v2.
 	TLSCACert string
 
-	// Path to TLS certificate file
+	// Path to TLS certificate file. Passed to the docker CLI on v2.
 	TLSCert string
 
-	// Path to TLS key file
+	// Path to TLS key file. Passed to the docker CLI on v2.
 	TLSKey string
 
-	// Use TLS and verify the remote
+	// Use TLS and verify the remote. Passed to the docker CLI on v2.
 	TLSVerify *bool
 
-	// Don't check the daemon's hostname against the name specified in the client certificate
+	// Don't check the daemon's hostname against the name specified in the client certificate. Docker Compose v1 only.
 	SkipHostnameCheck bool
 
-	// Specify an alternate working directory (default: the path of the Compose file)
+	// Specify an alternate working directory (default: the path of the first Compose file)
+	//
+	// When multiple Files are given, relative paths in all of them are resolved against this directory.
 	ProjectDirectory string
 
 	// If set, Compose will attempt to convert deploy keys in v3 files to their non-Swarm equivalent
 	Compatibility *bool
+
+	// How Files are merged when these options are used as an override (default: append). See Merge.
+	FilesMerge ListMergeMode
+
+	// How InlineFiles are merged when these options are used as an override (default: append). Files are removed by Name. See Merge.
+	InlineFilesMerge ListMergeMode
+
+	// How Profiles are merged when these options are used as an override (default: append). See Merge.
+	ProfilesMerge ListMergeMode
+
+	// How EnvFiles are merged when these options are used as an override (default: append). See Merge.
+	EnvFilesMerge ListMergeMode
+
+	// Fields to reset to their zero value when these options are used as an override. See Merge.
+	Clear []GlobalOptionField
 }
 
-func (c *ComposeClient) globalFlags(overrides ...*GlobalOptions) string {
+// globalFlags returns the flags for the docker CLI and for docker compose, merging the client's GlobalOptions with the given overrides
+func (c *ComposeClient) globalFlags(overrides ...*GlobalOptions) (string, string, error) {
+	dockerFlags := ""
 	flags := ""
+	compat := c.compat()
+	opts := c.GlobalOptions.Merge(overrides...)
 
-	if c.GlobalOptions != nil {
-		for _, file := range c.GlobalOptions.Files {
-			flags = fmt.Sprintf("%s --file %s", flags, file)
-		}
+	for _, file := range opts.Files {
+		flags = fmt.Sprintf("%s --file %s", flags, file)
 	}
 
-	for _, override := range overrides {
-		for _, file := range override.Files {
-			flags = fmt.Sprintf("%s --file %s", flags, file)
-		}
+	for _, profile := range opts.Profiles {
+		flags = fmt.Sprintf("%s --profile %s", flags, profile)
 	}
 
-	if c.GlobalOptions != nil {
-		for _, profile := range c.GlobalOptions.Profiles {
-			flags = fmt.Sprintf("%s --profile %s", flags, profile)
-		}
+	for _, envFile := range opts.EnvFiles {
+		flags = fmt.Sprintf("%s --env-file %s", flags, envFile)
 	}
 
-	for _, override := range overrides {
-		for _, profile := range override.Profiles {
-			flags = fmt.Sprintf("%s --profile %s", flags, profile)
-		}
+	// Connection flags belong to the docker CLI when compose runs as a v2 plugin
+	connectionFlags := &flags
+
+	if compat.v2() {
+		connectionFlags = &dockerFlags
 	}
 
-	var (
-		projectName      string
-		verbose          *bool
-		noANSI           *bool
-		host             string
-		tls              *bool
-		tlsCACert        string
-		tlsCert          string
-		tlsKey           string
-		tlsVerify        *bool
-		projectDirectory string
-		compatibility    *bool
-	)
-
-	if c.GlobalOptions != nil {
-		projectName = c.GlobalOptions.ProjectName
-		verbose = c.GlobalOptions.Verbose
-		noANSI = c.GlobalOptions.NoANSI
-		host = c.GlobalOptions.Host
-		tls = c.GlobalOptions.TLS
-		tlsCACert = c.GlobalOptions.TLSCACert
-		tlsCert = c.GlobalOptions.TLSCert
-		tlsKey = c.GlobalOptions.TLSKey
-		tlsVerify = c.GlobalOptions.TLSVerify
-		projectDirectory = c.GlobalOptions.ProjectDirectory
-		compatibility = c.GlobalOptions.Compatibility
-	}
-
-	for _, override := range overrides {
-		if override.ProjectName != "" {
-			projectName = override.ProjectName
-		}
+	if opts.ProjectName != "" {
+		flags = fmt.Sprintf("%s --project-name %s", flags, opts.ProjectName)
+	}
 
-		if override.Verbose != nil {
-			verbose = override.Verbose
+	if opts.Parallel != nil {
+		if err := compat.require("--parallel", "2.14.0"); err != nil {
+			return "", "", err
 		}
 
-		if override.NoANSI != nil {
-			noANSI = override.NoANSI
-		}
+		flags = fmt.Sprintf("%s --parallel %d", flags, *opts.Parallel)
+	}
 
-		if override.Host != "" {
-			host = override.Host
-		}
+	ansi := opts.ANSI
+	noANSI := opts.NoANSI != nil && *opts.NoANSI
 
-		if override.TLS != nil {
-			tls = override.TLS
-		}
+	if noANSI && ansi == "" && compat.v2() {
+		ansi = ANSIFlagNever
+	}
 
-		if override.TLSCACert != "" {
-			tlsCACert = override.TLSCACert
-		}
+	if ansi != "" {
+		flags = fmt.Sprintf("%s --ansi %s", flags, ansi)
+	} else if noANSI {
+		flags = fmt.Sprintf("%s --no-ansi", flags)
+	}
 
-		if override.TLSCert != "" {
-			tlsCert = override.TLSCert
+	if opts.Progress != "" {
+		if err := compat.require("--progress", "2.20.0"); err != nil {
+			return "", "", err
 		}
 
-		if override.TLSKey != "" {
-			tlsKey = override.TLSKey
-		}
+		flags = fmt.Sprintf("%s --progress %s", flags, opts.Progress)
+	}
 
-		if override.TLSVerify != nil {
-			tlsVerify = override.TLSVerify
+	if opts.DryRun != nil && *opts.DryRun {
+		if err := compat.require("--dry-run", "2.14.0"); err != nil {
+			return "", "", err
 		}
 
-		if override.ProjectDirectory != "" {
-			projectDirectory = override.ProjectDirectory
-		}
+		flags = fmt.Sprintf("%s --dry-run", flags)
+	}
 
-		if override.Compatibility != nil {
-			compatibility = override.Compatibility
+	if opts.Verbose != nil && *opts.Verbose {
+		if compat.v2() {
+			dockerFlags = fmt.Sprintf("%s --debug", dockerFlags)
+		} else {
+			flags = fmt.Sprintf("%s --verbose", flags)
 		}
 	}
 
-	if projectName != "" {
-		flags = fmt.Sprintf("%s --project-name %s", flags, projectName)
+	// The docker CLI rejects --host with --context, and a context has its own TLS settings, so the context takes precedence
+	if opts.Context != "" {
+		*connectionFlags = fmt.Sprintf("%s --context %s", *connectionFlags, opts.Context)
+	} else {
+		*connectionFlags += hostFlags(opts)
 	}
 
-	if verbose != nil && *verbose {
-		flags = fmt.Sprintf("%s --verbose", flags)
+	if opts.SkipHostnameCheck && !compat.v2() {
+		flags = fmt.Sprintf("%s --skip-hostname-check", flags)
 	}
 
-	if noANSI != nil && *noANSI {
-		flags = fmt.Sprintf("%s --no-ansi", flags)
+	if opts.ProjectDirectory != "" {
+		flags = fmt.Sprintf("%s --project-directory %s", flags, opts.ProjectDirectory)
 	}
 
-	if host != "" {
-		flags = fmt.Sprintf("%s --host %s", flags, host)
+	if opts.Compatibility != nil && *opts.Compatibility {
+		flags = fmt.Sprintf("%s --compatibility", flags)
 	}
 
-	if tls != nil && *tls {
-		flags = fmt.Sprintf("%s --tls", flags)
-	}
+	return dockerFlags, flags, nil
+}
+
+// hostFlags returns the Host and TLS flags, which are only used when no Context is set
+func hostFlags(opts *GlobalOptions) string {
+	flags := ""
 
-	if tlsCACert != "" {
-		flags = fmt.Sprintf("%s --tlscacert %s", flags, tlsCACert)
+	if opts.Host != "" {
+		flags = fmt.Sprintf("%s --host %s", flags, opts.Host)
 	}
 
-	if tlsCert != "" {
-		flags = fmt.Sprintf("%s --tlscert %s", flags, tlsCert)
+	if opts.TLS != nil && *opts.TLS {
+		flags = fmt.Sprintf("%s --tls", flags)
 	}
 
-	if tlsKey != "" {
-		flags = fmt.Sprintf("%s --tlskey %s", flags, tlsKey)
+	if opts.TLSCACert != "" {
+		flags = fmt.Sprintf("%s --tlscacert %s", flags, opts.TLSCACert)
 	}
 
-	if tlsVerify != nil && *tlsVerify {
-		flags = fmt.Sprintf("%s --tlsverify", flags)
+	if opts.TLSCert != "" {
+		flags = fmt.Sprintf("%s --tlscert %s", flags, opts.TLSCert)
 	}
 
-	if projectDirectory != "" {
-		flags = fmt.Sprintf("%s --project-directory %s", flags, projectDirectory)
+	if opts.TLSKey != "" {
+		flags = fmt.Sprintf("%s --tlskey %s", flags, opts.TLSKey)
 	}
 
-	if compatibility != nil && *compatibility {
-		flags = fmt.Sprintf("%s --compatibility", flags)
+	if opts.TLSVerify != nil && *opts.TLSVerify {
+		flags = fmt.Sprintf("%s --tlsverify", flags)
 	}
 
 	return flags
@@ -231,10 +287,58 @@ func (c *ComposeClient) globalFlags(overrides ...*GlobalOptions) string {
 //
 // stdout and stderr from the underlying docker compose processes are written to the given io.Writers
 //
+// The overrides are merged onto the client's GlobalOptions with GlobalOptions.Merge
+//
 // Users would normally use of one of the specific command methods (e.g. Up, Down)
 func (client *ComposeClient) RunCommand(command, flags string, stdout, stderr io.Writer, overrides ...*GlobalOptions) (<-chan error, error) {
+	return client.RunCommandWithStdin(command, flags, nil, stdout, stderr, overrides...)
+}
+
+// RunCommandWithStdin executes the given docker compose command in the same way as RunCommand, with stdin read by the
+// underlying docker compose process, e.g. a compose file passed with `--file -`
+func (client *ComposeClient) RunCommandWithStdin(command, flags string, stdin io.Reader, stdout, stderr io.Writer, overrides ...*GlobalOptions) (<-chan error, error) {
+	return processErrors(client.RunProcessWithStdin(command, flags, stdin, stdout, stderr, overrides...))
+}
+
+// RunProcess executes the given docker compose command in the same way as RunCommand, returning a Process handle.
+//
+// Users would normally use of one of the specific command methods (e.g. UpProcess, DownProcess)
+func (client *ComposeClient) RunProcess(command, flags string, stdout, stderr io.Writer, overrides ...*GlobalOptions) (*Process, error) {
+	return client.RunProcessWithStdin(command, flags, nil, stdout, stderr, overrides...)
+}
+
+// RunProcessWithStdin executes the given docker compose command in the same way as RunCommandWithStdin, returning a Process handle.
+func (client *ComposeClient) RunProcessWithStdin(command, flags string, stdin io.Reader, stdout, stderr io.Writer, overrides ...*GlobalOptions) (*Process, error) {
+	cmd, started, ch, err := client.run(command, flags, stdin, stdout, stderr, overrides...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return newProcess(cmd, started, ch), nil
+}
+
+// run starts the given docker compose command, returning the Cmd running it, when it was started and its result channel
+func (client *ComposeClient) run(command, flags string, stdin io.Reader, stdout, stderr io.Writer, overrides ...*GlobalOptions) (Cmd, time.Time, <-chan error, error) {
+	inline, stdin, cleanup, err := client.inlineFiles(stdin, overrides...)
+
+	if err != nil {
+		return nil, time.Time{}, nil, err
+	}
+
+	dockerFlags, globalFlags, err := client.globalFlags(append(overrides[:len(overrides):len(overrides)], inline)...)
+
+	if err != nil {
+		cleanup()
+		return nil, time.Time{}, nil, err
+	}
+
 	cmd := client.NewCmd()
 
+	if stdin != nil {
+		cmd.SetStdin(stdin)
+	}
+
 	if stdout != nil {
 		cmd.SetStdout(stdout)
 	}
@@ -243,33 +347,56 @@ func (client *ComposeClient) RunCommand(command, flags string, stdout, stderr io
 		cmd.SetStderr(stderr)
 	}
 
-	return cmd.Run(strings.TrimSpace(fmt.Sprintf("docker compose%s %s %s", client.globalFlags(overrides...), command, flags)))
+	started := time.Now()
+
+	ch, err := cmd.Run(strings.TrimSpace(fmt.Sprintf("docker%s compose%s %s %s", dockerFlags, globalFlags, command, flags)))
+
+	if err != nil {
+		cleanup()
+		return nil, time.Time{}, nil, err
+	}
+
+	ch = afterCompletion(ch, cleanup)
+
+	if shutdown := client.shutdownHook(); shutdown != nil {
+		ch = shutdown.track(cmd, ch)
+	}
+
+	return cmd, started, ch, nil
 }
 
-// RunQuery executes the given docker compose query, and returns the stdout stream as a byte array.
+// afterCompletion returns a channel that forwards ch once fn has been called
+func afterCompletion(ch <-chan error, fn func()) <-chan error {
+	out := make(chan error)
+
+	go func() {
+		defer close(out)
+
+		err := <-ch
+
+		fn()
+
+		out <- err
+	}()
+
+	return out
+}
+
+// RunQuery executes the given docker compose query, and returns its stdout exactly as it was written.
 //
-// Users would normally use of one of the specific query methods (e.g. Version)
+// Users would normally use of one of the specific query methods (e.g. Version). Use `StreamQuery` for large outputs.
 func (client *ComposeClient) RunQuery(command, flags string, overrides ...*GlobalOptions) ([]byte, error) {
 	var stdout bytes.Buffer
-	var result []byte
 
 	ch, err := client.RunCommand(command, flags, &stdout, nil, overrides...)
 
 	if err != nil {
-		return result, err
-	}
-
-	err = <-ch
-
-	if err != nil {
-		return result, err
+		return nil, err
 	}
 
-	scanner := bufio.NewScanner(&stdout)
-
-	for scanner.Scan() {
-		result = append(result, scanner.Bytes()...)
+	if err := <-ch; err != nil {
+		return nil, err
 	}
 
-	return result, nil
+	return stdout.Bytes(), nil
 }
@@ -21,10 +21,16 @@ const (
 
 type MockCmd struct {
 	mock.Mock
+	Stdin  io.Reader
 	Stdout io.Writer
 	Stderr io.Writer
 }
 
+func (o *MockCmd) SetStdin(stdin io.Reader) {
+	o.Called(stdin)
+	o.Stdin = stdin
+}
+
 func (o *MockCmd) SetStdout(stdout io.Writer) {
 	o.Called(stdout)
 	o.Stdout = stdout
@@ -306,6 +312,73 @@ func TestClientOverrideNoANSI(t *testing.T) {
 	cmd.AssertExpectations(t)
 }
 
+func TestClientConfigContext(t *testing.T) {
+	cmd := &MockCmd{}
+
+	c := &client.ComposeClient{
+		GlobalOptions: &client.GlobalOptions{
+			Context: "my-context",
+		},
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	cmd.On("Run", "docker compose --context my-context foo bar")
+
+	c.RunCommand("foo", "bar", nil, nil)
+
+	cmd.AssertExpectations(t)
+}
+
+func TestClientOverrideContext(t *testing.T) {
+	cmd := &MockCmd{}
+
+	c := &client.ComposeClient{
+		ComposeVersion: "v2.24.0",
+		GlobalOptions: &client.GlobalOptions{
+			Context: "my-context",
+		},
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	cmd.On("Run", "docker --context override-context compose foo bar")
+
+	c.RunCommand("foo", "bar", nil, nil, &client.GlobalOptions{
+		Context: "override-context",
+	})
+
+	cmd.AssertExpectations(t)
+}
+
+func TestClientContextWithHost(t *testing.T) {
+	cmd := &MockCmd{}
+
+	tlsVerify := true
+
+	c := &client.ComposeClient{
+		ComposeVersion: "v2.24.0",
+		GlobalOptions: &client.GlobalOptions{
+			Host:      "tcp://my-host:2376",
+			TLSVerify: &tlsVerify,
+			TLSCert:   "cert.pem",
+		},
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	cmd.On("Run", "docker --context my-context compose foo bar")
+
+	c.RunCommand("foo", "bar", nil, nil, &client.GlobalOptions{
+		Context: "my-context",
+	})
+
+	cmd.AssertExpectations(t)
+}
+
 func TestClientConfigHost(t *testing.T) {
 	cmd := &MockCmd{}
 
@@ -644,6 +717,385 @@ func TestClientOverrideCompatibility(t *testing.T) {
 	cmd.AssertExpectations(t)
 }
 
+func TestClientConfigEnvFiles(t *testing.T) {
+	cmd := &MockCmd{}
+
+	c := &client.ComposeClient{
+		GlobalOptions: &client.GlobalOptions{
+			EnvFiles: []string{
+				".env",
+			},
+		},
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	cmd.On("Run", "docker compose --env-file .env foo bar")
+
+	c.RunCommand("foo", "bar", nil, nil)
+
+	cmd.AssertExpectations(t)
+}
+
+func TestClientOverrideEnvFiles(t *testing.T) {
+	cmd := &MockCmd{}
+
+	c := &client.ComposeClient{
+		GlobalOptions: &client.GlobalOptions{
+			EnvFiles: []string{
+				".env",
+			},
+		},
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	cmd.On("Run", "docker compose --env-file .env --env-file .env.local foo bar")
+
+	c.RunCommand("foo", "bar", nil, nil, &client.GlobalOptions{
+		EnvFiles: []string{
+			".env.local",
+		},
+	})
+
+	cmd.AssertExpectations(t)
+}
+
+func TestClientConfigParallel(t *testing.T) {
+	cmd := &MockCmd{}
+
+	parallel := 4
+
+	c := &client.ComposeClient{
+		GlobalOptions: &client.GlobalOptions{
+			Parallel: &parallel,
+		},
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	cmd.On("Run", "docker compose --parallel 4 foo bar")
+
+	c.RunCommand("foo", "bar", nil, nil)
+
+	cmd.AssertExpectations(t)
+}
+
+func TestClientOverrideParallel(t *testing.T) {
+	cmd := &MockCmd{}
+
+	parallel := 4
+
+	c := &client.ComposeClient{
+		GlobalOptions: &client.GlobalOptions{
+			Parallel: &parallel,
+		},
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	cmd.On("Run", "docker compose --parallel -1 foo bar")
+
+	override := -1
+
+	c.RunCommand("foo", "bar", nil, nil, &client.GlobalOptions{
+		Parallel: &override,
+	})
+
+	cmd.AssertExpectations(t)
+}
+
+func TestClientConfigANSI(t *testing.T) {
+	cmd := &MockCmd{}
+
+	c := &client.ComposeClient{
+		GlobalOptions: &client.GlobalOptions{
+			ANSI: client.ANSIFlagNever,
+		},
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	cmd.On("Run", "docker compose --ansi never foo bar")
+
+	c.RunCommand("foo", "bar", nil, nil)
+
+	cmd.AssertExpectations(t)
+}
+
+func TestClientOverrideANSI(t *testing.T) {
+	cmd := &MockCmd{}
+
+	c := &client.ComposeClient{
+		GlobalOptions: &client.GlobalOptions{
+			ANSI: client.ANSIFlagNever,
+		},
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	cmd.On("Run", "docker compose --ansi always foo bar")
+
+	c.RunCommand("foo", "bar", nil, nil, &client.GlobalOptions{
+		ANSI: client.ANSIFlagAlways,
+	})
+
+	cmd.AssertExpectations(t)
+}
+
+func TestClientConfigProgress(t *testing.T) {
+	cmd := &MockCmd{}
+
+	c := &client.ComposeClient{
+		GlobalOptions: &client.GlobalOptions{
+			Progress: client.ProgressFlagPlain,
+		},
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	cmd.On("Run", "docker compose --progress plain foo bar")
+
+	c.RunCommand("foo", "bar", nil, nil)
+
+	cmd.AssertExpectations(t)
+}
+
+func TestClientOverrideProgress(t *testing.T) {
+	cmd := &MockCmd{}
+
+	c := &client.ComposeClient{
+		GlobalOptions: &client.GlobalOptions{
+			Progress: client.ProgressFlagPlain,
+		},
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	cmd.On("Run", "docker compose --progress json foo bar")
+
+	c.RunCommand("foo", "bar", nil, nil, &client.GlobalOptions{
+		Progress: client.ProgressFlagJSON,
+	})
+
+	cmd.AssertExpectations(t)
+}
+
+func TestClientConfigDryRun(t *testing.T) {
+	cmd := &MockCmd{}
+
+	dryRun := true
+
+	c := &client.ComposeClient{
+		GlobalOptions: &client.GlobalOptions{
+			DryRun: &dryRun,
+		},
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	cmd.On("Run", "docker compose --dry-run foo bar")
+
+	c.RunCommand("foo", "bar", nil, nil)
+
+	cmd.AssertExpectations(t)
+}
+
+func TestClientOverrideDryRun(t *testing.T) {
+	cmd := &MockCmd{}
+
+	dryRun := true
+
+	c := &client.ComposeClient{
+		GlobalOptions: &client.GlobalOptions{
+			DryRun: &dryRun,
+		},
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	cmd.On("Run", "docker compose foo bar")
+
+	override := false
+
+	c.RunCommand("foo", "bar", nil, nil, &client.GlobalOptions{
+		DryRun: &override,
+	})
+
+	cmd.AssertExpectations(t)
+}
+
+func TestClientConfigSkipHostnameCheck(t *testing.T) {
+	cmd := &MockCmd{}
+
+	c := &client.ComposeClient{
+		GlobalOptions: &client.GlobalOptions{
+			SkipHostnameCheck: false,
+		},
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	cmd.On("Run", "docker compose foo bar")
+
+	c.RunCommand("foo", "bar", nil, nil)
+
+	cmd.AssertExpectations(t)
+}
+
+func TestClientOverrideSkipHostnameCheck(t *testing.T) {
+	cmd := &MockCmd{}
+
+	c := &client.ComposeClient{
+		GlobalOptions: &client.GlobalOptions{
+			SkipHostnameCheck: false,
+		},
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	cmd.On("Run", "docker compose --skip-hostname-check foo bar")
+
+	c.RunCommand("foo", "bar", nil, nil, &client.GlobalOptions{
+		SkipHostnameCheck: true,
+	})
+
+	cmd.AssertExpectations(t)
+}
+
+func TestClientV2TranslatesNoANSI(t *testing.T) {
+	cmd := &MockCmd{}
+
+	noANSI := true
+
+	c := &client.ComposeClient{
+		ComposeVersion: "v2.24.0",
+		GlobalOptions: &client.GlobalOptions{
+			NoANSI: &noANSI,
+		},
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	cmd.On("Run", "docker compose --ansi never foo bar")
+
+	c.RunCommand("foo", "bar", nil, nil)
+
+	cmd.AssertExpectations(t)
+}
+
+func TestClientV2TranslatesConnectionFlags(t *testing.T) {
+	cmd := &MockCmd{}
+
+	verbose := true
+	tlsVerify := true
+
+	c := &client.ComposeClient{
+		ComposeVersion: "v2.24.0",
+		GlobalOptions: &client.GlobalOptions{
+			ProjectName:       "my-project",
+			Verbose:           &verbose,
+			Host:              "tcp://127.0.0.1:2376",
+			TLSCACert:         "ca.pem",
+			TLSVerify:         &tlsVerify,
+			SkipHostnameCheck: true,
+		},
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	cmd.On("Run", "docker --debug --host tcp://127.0.0.1:2376 --tlscacert ca.pem --tlsverify compose --project-name my-project foo bar")
+
+	c.RunCommand("foo", "bar", nil, nil)
+
+	cmd.AssertExpectations(t)
+}
+
+func TestClientUnsupportedGlobalFlag(t *testing.T) {
+	cmd := &MockCmd{}
+
+	dryRun := true
+
+	c := &client.ComposeClient{
+		ComposeVersion: "1.29.2",
+		GlobalOptions: &client.GlobalOptions{
+			DryRun: &dryRun,
+		},
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	_, err := c.RunCommand("foo", "bar", nil, nil)
+
+	var unsupported *client.UnsupportedFlagError
+
+	if !errors.As(err, &unsupported) || unsupported.Flag != "--dry-run" {
+		t.Errorf("expected UnsupportedFlagError for --dry-run, got %v", err)
+	}
+
+	cmd.AssertNotCalled(t, "Run", mock.Anything)
+}
+
+func TestClientUnsupportedProgress(t *testing.T) {
+	cmd := &MockCmd{}
+
+	c := &client.ComposeClient{
+		ComposeVersion: "v2.17.3",
+		GlobalOptions: &client.GlobalOptions{
+			Progress: client.ProgressFlagPlain,
+		},
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	_, err := c.RunCommand("foo", "bar", nil, nil)
+
+	var unsupported *client.UnsupportedFlagError
+
+	if !errors.As(err, &unsupported) || unsupported.Flag != "--progress" || unsupported.MinVersion != "2.20.0" {
+		t.Errorf("expected UnsupportedFlagError for --progress, got %v", err)
+	}
+
+	cmd.AssertNotCalled(t, "Run", mock.Anything)
+}
+
+func TestRunCommandStdinReader(t *testing.T) {
+	cmd := &MockCmd{}
+
+	stdin := strings.NewReader("services: {}")
+
+	c := &client.ComposeClient{
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	cmd.On("SetStdin", stdin)
+	cmd.On("Run", "docker compose --file - foo bar")
+
+	c.RunCommandWithStdin("foo", "bar", stdin, nil, nil, &client.GlobalOptions{
+		Files: []string{"-"},
+	})
+
+	cmd.AssertExpectations(t)
+}
+
 func TestRunCommandStdoutWriter(t *testing.T) {
 	cmd := &MockCmd{}
 
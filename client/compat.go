@@ -0,0 +1,113 @@
+package client
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+var composeVersionRegexp = regexp.MustCompile(`^v?(\d+)\.(\d+)(?:\.(\d+))?`)
+
+// composeVersion is a parsed Docker Compose version
+type composeVersion struct {
+	major int
+	minor int
+	patch int
+}
+
+// parseComposeVersion parses versions like `v2.3.3`, `2.24.0-desktop.1` or `1.29.2`
+func parseComposeVersion(version string) (composeVersion, bool) {
+	match := composeVersionRegexp.FindStringSubmatch(version)
+
+	if match == nil {
+		return composeVersion{}, false
+	}
+
+	major, _ := strconv.Atoi(match[1])
+	minor, _ := strconv.Atoi(match[2])
+	patch, _ := strconv.Atoi(match[3])
+
+	return composeVersion{major, minor, patch}, true
+}
+
+func mustParseComposeVersion(version string) composeVersion {
+	v, ok := parseComposeVersion(version)
+
+	if !ok {
+		panic(fmt.Sprintf("invalid compose version %q", version))
+	}
+
+	return v
+}
+
+func (v composeVersion) atLeast(other composeVersion) bool {
+	if v.major != other.major {
+		return v.major > other.major
+	}
+
+	if v.minor != other.minor {
+		return v.minor > other.minor
+	}
+
+	return v.patch >= other.patch
+}
+
+func (v composeVersion) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.major, v.minor, v.patch)
+}
+
+// UnsupportedFlagError is returned when an option requires a newer version of Docker Compose than the one in use
+type UnsupportedFlagError struct {
+	Flag       string
+	MinVersion string
+	Version    string
+}
+
+func (e *UnsupportedFlagError) Error() string {
+	return fmt.Sprintf("%s requires docker compose %s or later, but %s is in use", e.Flag, e.MinVersion, e.Version)
+}
+
+// compat reports which flags the client's Docker Compose version supports.
+//
+// If the version is unknown, every flag is assumed to be supported and nothing is translated.
+type compat struct {
+	version composeVersion
+	known   bool
+}
+
+func (c *ComposeClient) compat() compat {
+	v, ok := parseComposeVersion(c.ComposeVersion)
+
+	return compat{v, ok}
+}
+
+// v2 reports whether Docker Compose v2 or later is known to be in use
+func (c compat) v2() bool {
+	return c.known && c.version.major >= 2
+}
+
+// require returns an UnsupportedFlagError if the version is known and older than min
+func (c compat) require(flag, min string) error {
+	if !c.known || c.version.atLeast(mustParseComposeVersion(min)) {
+		return nil
+	}
+
+	return &UnsupportedFlagError{
+		Flag:       flag,
+		MinVersion: min,
+		Version:    c.version.String(),
+	}
+}
+
+// DetectVersion queries the version of Docker Compose in use and stores it in ComposeVersion, so that options can be translated to the flags it supports.
+func (c *ComposeClient) DetectVersion() (*Version, error) {
+	v, err := c.Version()
+
+	if err != nil {
+		return nil, err
+	}
+
+	c.ComposeVersion = v.Version
+
+	return v, nil
+}
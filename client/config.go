@@ -1,8 +1,12 @@
 package client
 
 import (
+	"bufio"
+	"bytes"
 	"fmt"
 	"strings"
+
+	"github.com/harrim91/docker-compose-go/compose"
 )
 
 type ConfigOptions struct {
@@ -16,16 +20,26 @@ type ConfigOptions struct {
 	Quiet bool
 
 	// Print the service names, one per line. Takes precedence over `Volumes` flag.
-	// This will make the function return a []byte representing a JSON string array of service names.
+	// Use `ConfigServices` to get the parsed list.
 	Services bool
 
 	// Print the volume names, one per line. Takes precedence over `Hash` flag.
-	// This will make the function return a []byte representing a JSON string array of volume names.
+	// Use `ConfigVolumes` to get the parsed list.
 	Volumes bool
 
 	// Print the service config hash, one per line.
 	// Set "service1,service2" for a list of specified services or use the wildcard symbol "*" to display all services.
+	// Use `ConfigHashes` to get the parsed hashes.
 	Hash string
+
+	// Print the network names, one per line. Use `ConfigNetworks` to get the parsed list.
+	Networks bool
+
+	// Print the image names, one per line. Use `ConfigImages` to get the parsed list.
+	Images bool
+
+	// Print the profile names, one per line. Use `ConfigProfiles` to get the parsed list.
+	Profiles bool
 }
 
 func configFlags(opts *ConfigOptions) string {
@@ -55,6 +69,18 @@ func configFlags(opts *ConfigOptions) string {
 		if opts.Hash != "" {
 			flags = fmt.Sprintf("%s --hash=\"%s\"", flags, opts.Hash)
 		}
+
+		if opts.Networks {
+			flags = fmt.Sprintf("%s --networks", flags)
+		}
+
+		if opts.Images {
+			flags = fmt.Sprintf("%s --images", flags)
+		}
+
+		if opts.Profiles {
+			flags = fmt.Sprintf("%s --profiles", flags)
+		}
 	}
 
 	return strings.TrimSpace(flags)
@@ -69,6 +95,114 @@ func configFlags(opts *ConfigOptions) string {
 // If Services, Volumes or Hash options are specified, returns a byte array representing the list of services/volumes/hashes (one per line)
 //
 // https://docs.docker.com/compose/reference/config/
-func (c *ComposeClient) Config(opts *ConfigOptions) ([]byte, error) {
-	return c.RunQuery("config", configFlags(opts))
+func (c *ComposeClient) Config(opts *ConfigOptions, overrides ...*GlobalOptions) ([]byte, error) {
+	return c.RunQuery("config", configFlags(opts), overrides...)
+}
+
+// Returns the Compose file parsed into a compose.Project.
+//
+// The Quiet, Services, Volumes, Hash, Networks, Images and Profiles options change the output from the Compose file, so are ignored.
+func (c *ComposeClient) ConfigProject(opts *ConfigOptions, overrides ...*GlobalOptions) (*compose.Project, error) {
+	var projectOpts ConfigOptions
+
+	if opts != nil {
+		projectOpts = ConfigOptions{
+			ResolveImageDigests: opts.ResolveImageDigests,
+			NoInterpolate:       opts.NoInterpolate,
+		}
+	}
+
+	config, err := c.Config(&projectOpts, overrides...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return compose.ParseJSON(config)
+}
+
+// Returns the names of the services in the Compose file.
+//
+// https://docs.docker.com/compose/reference/config/
+func (c *ComposeClient) ConfigServices(overrides ...*GlobalOptions) ([]string, error) {
+	return c.configLines(&ConfigOptions{Services: true}, overrides...)
+}
+
+// Returns the names of the volumes in the Compose file.
+//
+// https://docs.docker.com/compose/reference/config/
+func (c *ComposeClient) ConfigVolumes(overrides ...*GlobalOptions) ([]string, error) {
+	return c.configLines(&ConfigOptions{Volumes: true}, overrides...)
+}
+
+// Returns the names of the networks in the Compose file.
+//
+// https://docs.docker.com/compose/reference/config/
+func (c *ComposeClient) ConfigNetworks(overrides ...*GlobalOptions) ([]string, error) {
+	return c.configLines(&ConfigOptions{Networks: true}, overrides...)
+}
+
+// Returns the images used by the services in the Compose file.
+//
+// https://docs.docker.com/compose/reference/config/
+func (c *ComposeClient) ConfigImages(overrides ...*GlobalOptions) ([]string, error) {
+	return c.configLines(&ConfigOptions{Images: true}, overrides...)
+}
+
+// Returns the profiles used by the services in the Compose file.
+//
+// https://docs.docker.com/compose/reference/config/
+func (c *ComposeClient) ConfigProfiles(overrides ...*GlobalOptions) ([]string, error) {
+	return c.configLines(&ConfigOptions{Profiles: true}, overrides...)
+}
+
+// Returns the config hash of each service, keyed by service name. These are the hashes compose labels containers with,
+// to decide whether to recreate them.
+//
+// Returns the hashes of the given services, or every service if none are given.
+//
+// https://docs.docker.com/compose/reference/config/
+func (c *ComposeClient) ConfigHashes(services []string, overrides ...*GlobalOptions) (map[string]string, error) {
+	hash := "*"
+
+	if len(services) > 0 {
+		hash = strings.Join(services, ",")
+	}
+
+	lines, err := c.configLines(&ConfigOptions{Hash: hash}, overrides...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	hashes := map[string]string{}
+
+	for _, line := range lines {
+		if fields := strings.Fields(line); len(fields) == 2 {
+			hashes[fields[0]] = fields[1]
+		}
+	}
+
+	return hashes, nil
+}
+
+// configLines runs a config query that outputs a value per line, and returns the lines
+func (c *ComposeClient) configLines(opts *ConfigOptions, overrides ...*GlobalOptions) ([]string, error) {
+	out, err := c.RunQuery("config", configFlags(opts), overrides...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []string
+
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	return lines, scanner.Err()
 }
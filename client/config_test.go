@@ -3,6 +3,7 @@ package client_test
 import (
 	"errors"
 	"io"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -16,6 +17,13 @@ type mockConfigCmd struct {
 	mock.Mock
 	stdout io.Writer
 	stderr io.Writer
+
+	// Written to stdout instead of configJSON, if set
+	output string
+}
+
+func (o *mockConfigCmd) SetStdin(stdin io.Reader) {
+	o.Called(stdin)
 }
 
 func (o *mockConfigCmd) SetStdout(stdout io.Writer) {
@@ -38,8 +46,14 @@ func (o *mockConfigCmd) Run(cmd string) (<-chan error, error) {
 	ch := make(chan error)
 
 	go func() {
+		output := configJSON
+
+		if o.output != "" {
+			output = o.output
+		}
+
 		if o.stdout != nil {
-			o.stdout.Write([]byte(configJSON))
+			o.stdout.Write([]byte(output))
 		}
 
 		ch <- nil
@@ -214,3 +228,120 @@ func TestConfigCommandHash(t *testing.T) {
 
 	cmd.AssertExpectations(t)
 }
+
+func TestConfigProject(t *testing.T) {
+	cmd := &mockConfigCmd{
+		output: `{"name": "my-app", "services": {"web": {"image": "nginx", "ports": [{"target": 80, "published": 8080}]}}}`,
+	}
+
+	c := &client.ComposeClient{
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	cmd.On("SetStdout", mock.Anything)
+
+	cmd.On("Run", "docker compose config --format json --no-interpolate")
+
+	project, err := c.ConfigProject(&client.ConfigOptions{
+		NoInterpolate: true,
+		Services:      true,
+		Hash:          "*",
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cmd.AssertExpectations(t)
+
+	if project.Name != "my-app" || project.Services["web"].Image != "nginx" || project.Services["web"].Ports[0].Published != "8080" {
+		t.Errorf("unexpected project: %+v", project)
+	}
+}
+
+func TestConfigCommandOverrides(t *testing.T) {
+	cmd := &mockConfigCmd{}
+
+	c := &client.ComposeClient{
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	cmd.On("SetStdout", mock.Anything)
+
+	cmd.On("Run", "docker compose --file compose.old.yaml config --format json")
+
+	_, err := c.Config(nil, &client.GlobalOptions{
+		Files: []string{"compose.old.yaml"},
+	})
+
+	if err != nil {
+		t.Error(err)
+	}
+
+	cmd.AssertExpectations(t)
+}
+
+func TestConfigLists(t *testing.T) {
+	c := newQueryClient(map[string]string{
+		"docker compose config --format json --services": "web\ndb\n",
+		"docker compose config --format json --volumes":  "data\n",
+		"docker compose config --format json --networks": "default\nbackend\n",
+		"docker compose config --format json --images":   "nginx:1.25\npostgres:16\n",
+		"docker compose config --format json --profiles": "",
+	})
+
+	queries := []struct {
+		name     string
+		query    func(...*client.GlobalOptions) ([]string, error)
+		expected []string
+	}{
+		{"services", c.ConfigServices, []string{"web", "db"}},
+		{"volumes", c.ConfigVolumes, []string{"data"}},
+		{"networks", c.ConfigNetworks, []string{"default", "backend"}},
+		{"images", c.ConfigImages, []string{"nginx:1.25", "postgres:16"}},
+		{"profiles", c.ConfigProfiles, nil},
+	}
+
+	for _, q := range queries {
+		result, err := q.query()
+
+		if err != nil {
+			t.Errorf("%s: %s", q.name, err)
+		}
+
+		if !reflect.DeepEqual(result, q.expected) {
+			t.Errorf("%s: expected %q, got %q", q.name, q.expected, result)
+		}
+	}
+}
+
+func TestConfigHashes(t *testing.T) {
+	c := newQueryClient(map[string]string{
+		`docker compose config --format json --hash="*"`:                    "web 1a2b\ndb 3c4d\n",
+		`docker compose --file x.yaml config --format json --hash="web,db"`: "web 5e6f\ndb 3c4d\n",
+	})
+
+	hashes, err := c.ConfigHashes(nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := map[string]string{"web": "1a2b", "db": "3c4d"}; !reflect.DeepEqual(hashes, expected) {
+		t.Errorf("expected %v, got %v", expected, hashes)
+	}
+
+	hashes, err = c.ConfigHashes([]string{"web", "db"}, &client.GlobalOptions{Files: []string{"x.yaml"}})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := map[string]string{"web": "5e6f", "db": "3c4d"}; !reflect.DeepEqual(hashes, expected) {
+		t.Errorf("expected %v, got %v", expected, hashes)
+	}
+}
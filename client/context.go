@@ -0,0 +1,270 @@
+package client
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+const (
+	// The name of the implicit context that connects using the DOCKER_* environment variables.
+	DefaultDockerContext = "default"
+
+	defaultDockerHost = "unix:///var/run/docker.sock"
+)
+
+// DockerContext represents the connection settings of a Docker context.
+//
+// https://docs.docker.com/engine/context/working-with-contexts/
+type DockerContext struct {
+	Name string
+
+	Description string
+
+	// Daemon socket to connect to
+	Host string
+
+	// Don't verify the daemon's certificate
+	SkipTLSVerify bool
+
+	// Path to the CA certificate, if the context has one
+	TLSCACert string
+
+	// Path to the TLS certificate file, if the context has one
+	TLSCert string
+
+	// Path to the TLS key file, if the context has one
+	TLSKey string
+}
+
+// GlobalOptions returns the options needed to connect to the context's daemon.
+//
+// These can be used as the client's GlobalOptions or passed as an override to any command.
+func (d *DockerContext) GlobalOptions() *GlobalOptions {
+	opts := &GlobalOptions{
+		Host:      d.Host,
+		TLSCACert: d.TLSCACert,
+		TLSCert:   d.TLSCert,
+		TLSKey:    d.TLSKey,
+	}
+
+	if d.TLSCACert != "" || d.TLSCert != "" || d.TLSKey != "" {
+		tls := true
+
+		if d.SkipTLSVerify {
+			opts.TLS = &tls
+		} else {
+			opts.TLSVerify = &tls
+		}
+	}
+
+	return opts
+}
+
+// Env returns the DOCKER_HOST, DOCKER_CERT_PATH and DOCKER_TLS_VERIFY environment variables equivalent to the context.
+func (d *DockerContext) Env() []string {
+	env := []string{fmt.Sprintf("DOCKER_HOST=%s", d.Host)}
+
+	if d.TLSCACert != "" {
+		env = append(env, fmt.Sprintf("DOCKER_CERT_PATH=%s", filepath.Dir(d.TLSCACert)))
+
+		if !d.SkipTLSVerify {
+			env = append(env, "DOCKER_TLS_VERIFY=1")
+		}
+	}
+
+	return env
+}
+
+// DockerContextStore reads Docker contexts from a Docker CLI configuration directory
+type DockerContextStore struct {
+	// The Docker CLI configuration directory (default: $DOCKER_CONFIG or ~/.docker)
+	ConfigDir string
+}
+
+// NewDockerContextStore returns a DockerContextStore for the current user's Docker CLI configuration directory
+func NewDockerContextStore() (*DockerContextStore, error) {
+	dir := os.Getenv("DOCKER_CONFIG")
+
+	if dir == "" {
+		home, err := os.UserHomeDir()
+
+		if err != nil {
+			return nil, err
+		}
+
+		dir = filepath.Join(home, ".docker")
+	}
+
+	return &DockerContextStore{ConfigDir: dir}, nil
+}
+
+// dockerContextMeta mirrors the meta.json files written by `docker context create`
+type dockerContextMeta struct {
+	Name     string `json:"Name"`
+	Metadata struct {
+		Description string `json:"Description"`
+	} `json:"Metadata"`
+	Endpoints map[string]struct {
+		Host          string `json:"Host"`
+		SkipTLSVerify bool   `json:"SkipTLSVerify"`
+	} `json:"Endpoints"`
+}
+
+// contextID returns the directory name the Docker CLI uses to store a context
+func contextID(name string) string {
+	sum := sha256.Sum256([]byte(name))
+	return hex.EncodeToString(sum[:])
+}
+
+// Load returns the named context.
+//
+// The `default` context is derived from the DOCKER_HOST, DOCKER_CERT_PATH and DOCKER_TLS_VERIFY environment variables.
+func (s *DockerContextStore) Load(name string) (*DockerContext, error) {
+	if name == DefaultDockerContext {
+		return defaultDockerContext(), nil
+	}
+
+	b, err := os.ReadFile(filepath.Join(s.ConfigDir, "contexts", "meta", contextID(name), "meta.json"))
+
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("docker context %q not found", name)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return s.parse(b)
+}
+
+// List returns every context in the store, including `default`, sorted by name.
+func (s *DockerContextStore) List() ([]*DockerContext, error) {
+	contexts := []*DockerContext{defaultDockerContext()}
+
+	entries, err := os.ReadDir(filepath.Join(s.ConfigDir, "contexts", "meta"))
+
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		b, err := os.ReadFile(filepath.Join(s.ConfigDir, "contexts", "meta", entry.Name(), "meta.json"))
+
+		if err != nil {
+			continue
+		}
+
+		context, err := s.parse(b)
+
+		if err != nil {
+			return nil, err
+		}
+
+		contexts = append(contexts, context)
+	}
+
+	sort.Slice(contexts, func(i, j int) bool {
+		return contexts[i].Name < contexts[j].Name
+	})
+
+	return contexts, nil
+}
+
+// Current returns the name of the context the Docker CLI would use.
+//
+// In order of precedence: $DOCKER_CONTEXT, `default` if $DOCKER_HOST is set, then `currentContext` from config.json.
+func (s *DockerContextStore) Current() (string, error) {
+	if name := os.Getenv("DOCKER_CONTEXT"); name != "" {
+		return name, nil
+	}
+
+	if os.Getenv("DOCKER_HOST") != "" {
+		return DefaultDockerContext, nil
+	}
+
+	b, err := os.ReadFile(filepath.Join(s.ConfigDir, "config.json"))
+
+	if errors.Is(err, os.ErrNotExist) {
+		return DefaultDockerContext, nil
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	var config struct {
+		CurrentContext string `json:"currentContext"`
+	}
+
+	if err := json.Unmarshal(b, &config); err != nil {
+		return "", err
+	}
+
+	if config.CurrentContext == "" {
+		return DefaultDockerContext, nil
+	}
+
+	return config.CurrentContext, nil
+}
+
+func (s *DockerContextStore) parse(b []byte) (*DockerContext, error) {
+	var meta dockerContextMeta
+
+	if err := json.Unmarshal(b, &meta); err != nil {
+		return nil, err
+	}
+
+	endpoint := meta.Endpoints["docker"]
+
+	context := &DockerContext{
+		Name:          meta.Name,
+		Description:   meta.Metadata.Description,
+		Host:          endpoint.Host,
+		SkipTLSVerify: endpoint.SkipTLSVerify,
+	}
+
+	tlsDir := filepath.Join(s.ConfigDir, "contexts", "tls", contextID(meta.Name), "docker")
+
+	context.TLSCACert = existingFile(filepath.Join(tlsDir, "ca.pem"))
+	context.TLSCert = existingFile(filepath.Join(tlsDir, "cert.pem"))
+	context.TLSKey = existingFile(filepath.Join(tlsDir, "key.pem"))
+
+	return context, nil
+}
+
+func defaultDockerContext() *DockerContext {
+	context := &DockerContext{
+		Name:        DefaultDockerContext,
+		Description: "Current DOCKER_HOST based configuration",
+		Host:        os.Getenv("DOCKER_HOST"),
+	}
+
+	if context.Host == "" {
+		context.Host = defaultDockerHost
+	}
+
+	if certPath := os.Getenv("DOCKER_CERT_PATH"); certPath != "" {
+		context.TLSCACert = existingFile(filepath.Join(certPath, "ca.pem"))
+		context.TLSCert = existingFile(filepath.Join(certPath, "cert.pem"))
+		context.TLSKey = existingFile(filepath.Join(certPath, "key.pem"))
+	}
+
+	context.SkipTLSVerify = os.Getenv("DOCKER_TLS_VERIFY") == ""
+
+	return context
+}
+
+// existingFile returns path if it exists, or an empty string otherwise
+func existingFile(path string) string {
+	if _, err := os.Stat(path); err != nil {
+		return ""
+	}
+
+	return path
+}
@@ -0,0 +1,180 @@
+package client_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/harrim91/docker-compose-go/client"
+)
+
+func writeDockerContext(t *testing.T, dir, name, meta string, tlsFiles ...string) {
+	sum := sha256.Sum256([]byte(name))
+	id := hex.EncodeToString(sum[:])
+
+	metaDir := filepath.Join(dir, "contexts", "meta", id)
+
+	if err := os.MkdirAll(metaDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(metaDir, "meta.json"), []byte(meta), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tlsDir := filepath.Join(dir, "contexts", "tls", id, "docker")
+
+	if err := os.MkdirAll(tlsDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, file := range tlsFiles {
+		if err := os.WriteFile(filepath.Join(tlsDir, file), []byte("pem"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestDockerContextStoreLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	writeDockerContext(t, dir, "remote", `{"Name":"remote","Metadata":{"Description":"CI daemon"},"Endpoints":{"docker":{"Host":"tcp://10.0.0.1:2376","SkipTLSVerify":false}}}`, "ca.pem", "cert.pem", "key.pem")
+
+	store := &client.DockerContextStore{ConfigDir: dir}
+
+	context, err := store.Load("remote")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if context.Host != "tcp://10.0.0.1:2376" || context.Description != "CI daemon" {
+		t.Errorf("unexpected context: %+v", context)
+	}
+
+	opts := context.GlobalOptions()
+
+	if opts.Host != "tcp://10.0.0.1:2376" || opts.TLSVerify == nil || !*opts.TLSVerify || opts.TLS != nil {
+		t.Errorf("unexpected options: %+v", opts)
+	}
+
+	if filepath.Base(opts.TLSCACert) != "ca.pem" || filepath.Base(opts.TLSCert) != "cert.pem" || filepath.Base(opts.TLSKey) != "key.pem" {
+		t.Errorf("unexpected TLS files: %+v", opts)
+	}
+
+	env := context.Env()
+
+	if len(env) != 3 || env[0] != "DOCKER_HOST=tcp://10.0.0.1:2376" || env[2] != "DOCKER_TLS_VERIFY=1" {
+		t.Errorf("unexpected env: %v", env)
+	}
+}
+
+func TestDockerContextStoreLoadWithoutTLS(t *testing.T) {
+	dir := t.TempDir()
+
+	writeDockerContext(t, dir, "local", `{"Name":"local","Endpoints":{"docker":{"Host":"unix:///run/user/1000/docker.sock"}}}`)
+
+	store := &client.DockerContextStore{ConfigDir: dir}
+
+	context, err := store.Load("local")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := context.GlobalOptions()
+
+	if opts.Host != "unix:///run/user/1000/docker.sock" || opts.TLS != nil || opts.TLSVerify != nil || opts.TLSCACert != "" {
+		t.Errorf("unexpected options: %+v", opts)
+	}
+}
+
+func TestDockerContextStoreLoadNotFound(t *testing.T) {
+	store := &client.DockerContextStore{ConfigDir: t.TempDir()}
+
+	_, err := store.Load("missing")
+
+	if err == nil {
+		t.Error("expected an error")
+	}
+}
+
+func TestDockerContextStoreDefault(t *testing.T) {
+	certPath := t.TempDir()
+
+	for _, file := range []string{"ca.pem", "cert.pem", "key.pem"} {
+		os.WriteFile(filepath.Join(certPath, file), []byte("pem"), 0o600)
+	}
+
+	t.Setenv("DOCKER_HOST", "tcp://192.168.99.100:2376")
+	t.Setenv("DOCKER_CERT_PATH", certPath)
+	t.Setenv("DOCKER_TLS_VERIFY", "1")
+
+	store := &client.DockerContextStore{ConfigDir: t.TempDir()}
+
+	context, err := store.Load(client.DefaultDockerContext)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if context.Host != "tcp://192.168.99.100:2376" || context.SkipTLSVerify || context.TLSCACert != filepath.Join(certPath, "ca.pem") {
+		t.Errorf("unexpected context: %+v", context)
+	}
+}
+
+func TestDockerContextStoreList(t *testing.T) {
+	dir := t.TempDir()
+
+	writeDockerContext(t, dir, "remote", `{"Name":"remote","Endpoints":{"docker":{"Host":"tcp://10.0.0.1:2376"}}}`)
+	writeDockerContext(t, dir, "another", `{"Name":"another","Endpoints":{"docker":{"Host":"tcp://10.0.0.2:2376"}}}`)
+
+	store := &client.DockerContextStore{ConfigDir: dir}
+
+	contexts, err := store.List()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	names := []string{"another", "default", "remote"}
+
+	if len(contexts) != len(names) {
+		t.Fatalf("expected %d contexts, got %d", len(names), len(contexts))
+	}
+
+	for i, name := range names {
+		if contexts[i].Name != name {
+			t.Errorf("expected context %d to be %s, got %s", i, name, contexts[i].Name)
+		}
+	}
+}
+
+func TestDockerContextStoreCurrent(t *testing.T) {
+	dir := t.TempDir()
+
+	os.WriteFile(filepath.Join(dir, "config.json"), []byte(`{"currentContext":"remote"}`), 0o644)
+
+	t.Setenv("DOCKER_CONTEXT", "")
+	t.Setenv("DOCKER_HOST", "")
+
+	store := &client.DockerContextStore{ConfigDir: dir}
+
+	name, err := store.Current()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if name != "remote" {
+		t.Errorf("expected remote, got %s", name)
+	}
+
+	t.Setenv("DOCKER_CONTEXT", "ci")
+
+	if name, _ := store.Current(); name != "ci" {
+		t.Errorf("expected DOCKER_CONTEXT to take precedence, got %s", name)
+	}
+}
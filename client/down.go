@@ -69,5 +69,26 @@ func downFlags(opts *DownOptions) string {
 //
 // https://docs.docker.com/compose/reference/down/
 func (client *ComposeClient) Down(opts *DownOptions, w io.Writer, overrides ...*GlobalOptions) (<-chan error, error) {
-	return client.RunCommand("down", downFlags(opts), nil, w, overrides...)
+	return processErrors(client.DownProcess(opts, w, overrides...))
+}
+
+// docker compose down
+//
+// Stops containers and removes containers, networks, volumes, and images created by `up`, returning a Process handle.
+//
+// stderr is written to the given io.Writer
+//
+// https://docs.docker.com/compose/reference/down/
+func (client *ComposeClient) DownProcess(opts *DownOptions, w io.Writer, overrides ...*GlobalOptions) (*Process, error) {
+	cmd, started, ch, err := client.run("down", downFlags(opts), nil, nil, w, overrides...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if shutdown := client.shutdownHook(); shutdown != nil {
+		ch = shutdown.untrackProject(overrides, ch)
+	}
+
+	return newProcess(cmd, started, ch), nil
 }
@@ -0,0 +1,337 @@
+package client
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/harrim91/docker-compose-go/compose"
+)
+
+// InlineFileMode controls how GlobalOptions.InlineFiles are passed to docker compose
+type InlineFileMode string
+
+const (
+	// Pass a single inline file on stdin if the command has no other input, or write temporary files otherwise. This is the default.
+	InlineFileModeAuto InlineFileMode = ""
+
+	// Pass the inline file on stdin with `--file -`. Only one inline file can be passed, and the command can't have other input.
+	InlineFileModeStdin InlineFileMode = "stdin"
+
+	// Write the inline files to a temporary directory, which is removed once the command has completed.
+	InlineFileModeTemp InlineFileMode = "temp"
+)
+
+const defaultInlineFileName = "compose.yaml"
+
+// The files compose looks for when none are passed, in order of preference
+var (
+	defaultComposeFileNames         = []string{"compose.yaml", "compose.yml", "docker-compose.yaml", "docker-compose.yml"}
+	defaultComposeOverrideFileNames = []string{"compose.override.yaml", "compose.override.yml", "docker-compose.override.yaml", "docker-compose.override.yml"}
+)
+
+// ComposeFile is a compose file supplied as YAML or JSON content rather than a path on disk.
+//
+// When all the compose files are inline, relative paths within them (e.g. build contexts) are resolved against
+// ProjectDirectory, or the current working directory if it isn't set.
+type ComposeFile struct {
+	// Names the temporary file, and identifies the file when removing it with InlineFilesMerge (default: compose.yaml)
+	Name string
+
+	// The content of the compose file
+	Content []byte
+
+	// The content is read from Reader when Content is nil. A Reader can only be read once, so should only be used for a single command.
+	//
+	// Methods that run several commands, e.g. UpWithResult, read it into Content first and clear Reader, so the file can be passed to each of them.
+	Reader io.Reader
+
+	// The file overrides the project's compose files rather than replacing them.
+	//
+	// Compose only looks for its default files (e.g. compose.yaml and compose.override.yaml, or those in COMPOSE_FILE)
+	// when no files are passed, so if Files is empty, the default files are found and passed before the inline files.
+	Override bool
+}
+
+func (f *ComposeFile) name() string {
+	if f.Name == "" {
+		return defaultInlineFileName
+	}
+
+	return filepath.Base(f.Name)
+}
+
+func (f *ComposeFile) reader() (io.Reader, error) {
+	if f.Content != nil {
+		return bytes.NewReader(f.Content), nil
+	}
+
+	if f.Reader != nil {
+		return f.Reader, nil
+	}
+
+	return nil, fmt.Errorf("inline compose file %s has no content", f.name())
+}
+
+// bufferInlineFiles reads the inline files supplied as a Reader on the client's options and the overrides into
+// Content, so they can be passed to several commands. The files are updated in place and their Reader cleared.
+func (c *ComposeClient) bufferInlineFiles(overrides ...*GlobalOptions) error {
+	for _, opts := range append([]*GlobalOptions{c.GlobalOptions}, overrides...) {
+		if opts == nil {
+			continue
+		}
+
+		for i := range opts.InlineFiles {
+			file := &opts.InlineFiles[i]
+
+			if file.Content != nil || file.Reader == nil {
+				continue
+			}
+
+			content, err := io.ReadAll(file.Reader)
+
+			if err != nil {
+				return err
+			}
+
+			file.Content = content
+			file.Reader = nil
+		}
+	}
+
+	return nil
+}
+
+// ProjectOptions returns options that pass the project to docker compose as an inline compose file.
+//
+// These can be passed as an override to any command, e.g. `c.Up(opts, w, projectOptions)`.
+func ProjectOptions(p *compose.Project) (*GlobalOptions, error) {
+	content, err := p.YAML()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &GlobalOptions{
+		InlineFiles: []ComposeFile{
+			{Name: defaultInlineFileName, Content: content},
+		},
+	}, nil
+}
+
+// inlineFiles prepares the merged InlineFiles to be passed to docker compose.
+//
+// It returns options to be applied on top of the overrides, the stdin for the command, and a function that removes any temporary files once the command has completed.
+func (c *ComposeClient) inlineFiles(stdin io.Reader, overrides ...*GlobalOptions) (*GlobalOptions, io.Reader, func(), error) {
+	opts := c.GlobalOptions.Merge(overrides...)
+	cleanup := func() {}
+
+	if len(opts.InlineFiles) == 0 {
+		return nil, stdin, cleanup, nil
+	}
+
+	var defaults []string
+
+	for _, file := range opts.InlineFiles {
+		if file.Override && len(opts.Files) == 0 {
+			var err error
+
+			if defaults, err = defaultComposeFiles(opts.ProjectDirectory); err != nil {
+				return nil, nil, nil, err
+			}
+
+			break
+		}
+	}
+
+	mode := opts.InlineFileMode
+
+	if mode == InlineFileModeAuto {
+		mode = InlineFileModeTemp
+
+		if len(opts.InlineFiles) == 1 && stdin == nil {
+			mode = InlineFileModeStdin
+		}
+	}
+
+	switch mode {
+	case InlineFileModeStdin:
+		if len(opts.InlineFiles) > 1 {
+			return nil, nil, nil, fmt.Errorf("only one inline compose file can be passed on stdin, got %d", len(opts.InlineFiles))
+		}
+
+		if stdin != nil {
+			return nil, nil, nil, fmt.Errorf("an inline compose file can't be passed on stdin to a command that has other input")
+		}
+
+		r, err := opts.InlineFiles[0].reader()
+
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		return &GlobalOptions{Files: append(defaults, "-")}, r, cleanup, nil
+
+	case InlineFileModeTemp:
+		inline := &GlobalOptions{Files: defaults}
+
+		if len(opts.Files) == 0 && len(defaults) == 0 && opts.ProjectDirectory == "" {
+			wd, err := os.Getwd()
+
+			if err != nil {
+				return nil, nil, nil, err
+			}
+
+			inline.ProjectDirectory = wd
+		}
+
+		dir, err := os.MkdirTemp("", "docker-compose-go-")
+
+		if err != nil {
+			return nil, nil, nil, err
+		}
+
+		cleanup = func() {
+			os.RemoveAll(dir)
+		}
+
+		for i, file := range opts.InlineFiles {
+			path, err := writeInlineFile(dir, i, &file)
+
+			if err != nil {
+				cleanup()
+				return nil, nil, nil, err
+			}
+
+			inline.Files = append(inline.Files, path)
+		}
+
+		return inline, stdin, cleanup, nil
+
+	default:
+		return nil, nil, nil, fmt.Errorf("unknown inline file mode %q", mode)
+	}
+}
+
+// defaultComposeFiles finds the compose files that compose would use if no files were passed. Like compose, it uses
+// COMPOSE_FILE if it is set in the environment or the `.env` file in the project directory (default: the working
+// directory). Otherwise it looks for the compose file, and override file if there is one, in the project directory
+// and then its parents.
+func defaultComposeFiles(dir string) ([]string, error) {
+	if dir == "" {
+		wd, err := os.Getwd()
+
+		if err != nil {
+			return nil, err
+		}
+
+		dir = wd
+	}
+
+	dir, err := filepath.Abs(dir)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if files := composeFileEnv(dir); len(files) > 0 {
+		return files, nil
+	}
+
+	for search := dir; ; search = filepath.Dir(search) {
+		if file := findFile(search, defaultComposeFileNames); file != "" {
+			files := []string{file}
+
+			if override := findFile(search, defaultComposeOverrideFileNames); override != "" {
+				files = append(files, override)
+			}
+
+			return files, nil
+		}
+
+		if filepath.Dir(search) == search {
+			return nil, fmt.Errorf("no compose file found in %s or its parents to apply the inline override files to", dir)
+		}
+	}
+}
+
+// composeFileEnv returns the files in COMPOSE_FILE, split on COMPOSE_PATH_SEPARATOR (default: the OS path list
+// separator), with relative paths resolved against dir. Variables set in the environment take precedence over those
+// in the `.env` file in dir. Returns nil if COMPOSE_FILE isn't set.
+func composeFileEnv(dir string) []string {
+	value := os.Getenv("COMPOSE_FILE")
+	separator := os.Getenv("COMPOSE_PATH_SEPARATOR")
+
+	if value == "" || separator == "" {
+		// A missing .env leaves the map nil, which has no variables
+		dotenv, _ := readEnvFile(filepath.Join(dir, ".env"))
+
+		if value == "" {
+			value = dotenv["COMPOSE_FILE"]
+		}
+
+		if separator == "" {
+			separator = dotenv["COMPOSE_PATH_SEPARATOR"]
+		}
+	}
+
+	if separator == "" {
+		separator = string(os.PathListSeparator)
+	}
+
+	var files []string
+
+	for _, file := range strings.Split(value, separator) {
+		if file == "" {
+			continue
+		}
+
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(dir, file)
+		}
+
+		files = append(files, file)
+	}
+
+	return files
+}
+
+// findFile returns the path of the first of the named files that exists in dir, or an empty string
+func findFile(dir string, names []string) string {
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+
+	return ""
+}
+
+// writeInlineFile writes the i-th inline file to dir, returning its path
+func writeInlineFile(dir string, i int, file *ComposeFile) (string, error) {
+	r, err := file.reader()
+
+	if err != nil {
+		return "", err
+	}
+
+	content, err := io.ReadAll(r)
+
+	if err != nil {
+		return "", err
+	}
+
+	// Prefixed with the index so files with the same name don't overwrite each other
+	path := filepath.Join(dir, fmt.Sprintf("%d-%s", i, file.name()))
+
+	if err := os.WriteFile(path, content, 0600); err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
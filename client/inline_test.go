@@ -0,0 +1,468 @@
+package client_test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/harrim91/docker-compose-go/client"
+	"github.com/harrim91/docker-compose-go/compose"
+	"github.com/stretchr/testify/mock"
+)
+
+// inlineCmd records the content of each compose file passed to the command, while it is running
+type inlineCmd struct {
+	stdin   io.Reader
+	command string
+	files   map[string]string
+}
+
+func (o *inlineCmd) SetStdin(stdin io.Reader) {
+	o.stdin = stdin
+}
+
+func (o *inlineCmd) SetStdout(stdout io.Writer) {}
+
+func (o *inlineCmd) SetStderr(stderr io.Writer) {}
+
+func (o *inlineCmd) Run(cmd string) (<-chan error, error) {
+	o.command = cmd
+	o.files = map[string]string{}
+
+	args := strings.Fields(cmd)
+
+	for i, arg := range args {
+		if arg != "--file" {
+			continue
+		}
+
+		var content []byte
+
+		if args[i+1] == "-" {
+			content, _ = io.ReadAll(o.stdin)
+		} else {
+			content, _ = os.ReadFile(args[i+1])
+		}
+
+		o.files[args[i+1]] = string(content)
+	}
+
+	ch := make(chan error, 1)
+	ch <- nil
+
+	return ch, nil
+}
+
+// fileArgs returns the paths passed with --file, in order
+func (o *inlineCmd) fileArgs() []string {
+	var files []string
+
+	args := strings.Fields(o.command)
+
+	for i, arg := range args {
+		if arg == "--file" {
+			files = append(files, args[i+1])
+		}
+	}
+
+	return files
+}
+
+func TestInlineFileStdin(t *testing.T) {
+	cmd := &inlineCmd{}
+
+	c := &client.ComposeClient{
+		GlobalOptions: &client.GlobalOptions{
+			InlineFiles: []client.ComposeFile{
+				{Reader: strings.NewReader("services: {}")},
+			},
+		},
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	ch, err := c.RunCommand("foo", "bar", nil, nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	<-ch
+
+	expected := "docker compose --file - foo bar"
+
+	if cmd.command != expected {
+		t.Errorf("expected %s, got %s", expected, cmd.command)
+	}
+
+	if cmd.files["-"] != "services: {}" {
+		t.Errorf("expected the inline file on stdin, got %q", cmd.files["-"])
+	}
+}
+
+func TestInlineFileTemp(t *testing.T) {
+	cmd := &inlineCmd{}
+
+	c := &client.ComposeClient{
+		GlobalOptions: &client.GlobalOptions{
+			Files: []string{"docker-compose.yml"},
+			InlineFiles: []client.ComposeFile{
+				{Name: "override.yml", Content: []byte("services: {web: {}}")},
+			},
+		},
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	ch, err := c.RunCommand("foo", "bar", nil, nil, &client.GlobalOptions{
+		InlineFiles: []client.ComposeFile{
+			{Content: []byte("services: {db: {}}")},
+		},
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	<-ch
+
+	files := cmd.fileArgs()
+
+	if len(files) != 3 || files[0] != "docker-compose.yml" {
+		t.Fatalf("expected the inline files after docker-compose.yml, got %v", files)
+	}
+
+	if !strings.HasSuffix(files[1], "override.yml") || cmd.files[files[1]] != "services: {web: {}}" {
+		t.Errorf("unexpected override file %s: %q", files[1], cmd.files[files[1]])
+	}
+
+	if !strings.HasSuffix(files[2], "compose.yaml") || cmd.files[files[2]] != "services: {db: {}}" {
+		t.Errorf("unexpected compose file %s: %q", files[2], cmd.files[files[2]])
+	}
+
+	if strings.Contains(cmd.command, "--project-directory") {
+		t.Errorf("expected no project directory when the first file is on disk, got %s", cmd.command)
+	}
+
+	for _, file := range files[1:] {
+		if _, err := os.Stat(file); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be removed once the command has completed", file)
+		}
+	}
+}
+
+func TestInlineFileTempProjectDirectory(t *testing.T) {
+	cmd := &inlineCmd{}
+
+	c := &client.ComposeClient{
+		GlobalOptions: &client.GlobalOptions{
+			InlineFileMode: client.InlineFileModeTemp,
+			InlineFiles: []client.ComposeFile{
+				{Content: []byte("services: {}")},
+			},
+		},
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	ch, err := c.RunCommand("foo", "bar", nil, nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	<-ch
+
+	wd, _ := os.Getwd()
+
+	if !strings.Contains(cmd.command, "--project-directory "+wd) {
+		t.Errorf("expected the working directory as the project directory, got %s", cmd.command)
+	}
+}
+
+func TestInlineFileOverride(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "compose.yaml"), []byte("services: {web: {}}"), 0600)
+	os.WriteFile(filepath.Join(dir, "compose.override.yml"), []byte("services: {db: {}}"), 0600)
+
+	cmd := &inlineCmd{}
+
+	c := &client.ComposeClient{
+		GlobalOptions: &client.GlobalOptions{
+			ProjectDirectory: dir,
+			InlineFiles: []client.ComposeFile{
+				{Content: []byte("services: {web: {image: nginx}}"), Override: true},
+			},
+		},
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	ch, err := c.RunCommand("foo", "bar", nil, nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	<-ch
+
+	files := cmd.fileArgs()
+	expected := []string{filepath.Join(dir, "compose.yaml"), filepath.Join(dir, "compose.override.yml"), "-"}
+
+	if strings.Join(files, " ") != strings.Join(expected, " ") {
+		t.Errorf("expected the override after the default files %v, got %v", expected, files)
+	}
+
+	if cmd.files["-"] != "services: {web: {image: nginx}}" {
+		t.Errorf("expected the override on stdin, got %q", cmd.files["-"])
+	}
+}
+
+func TestInlineFileOverrideNoComposeFile(t *testing.T) {
+	c := &client.ComposeClient{
+		GlobalOptions: &client.GlobalOptions{
+			ProjectDirectory: t.TempDir(),
+			InlineFiles: []client.ComposeFile{
+				{Content: []byte("services: {}"), Override: true},
+			},
+		},
+		NewCmd: func() client.Cmd {
+			return &inlineCmd{}
+		},
+	}
+
+	if _, err := c.RunCommand("foo", "bar", nil, nil); err == nil {
+		t.Error("expected an error when there is no compose file to override")
+	}
+}
+
+// overrideFileArgs returns the files passed to a command with an inline override file, and no Files
+func overrideFileArgs(t *testing.T, dir string) []string {
+	t.Helper()
+
+	cmd := &inlineCmd{}
+
+	c := &client.ComposeClient{
+		GlobalOptions: &client.GlobalOptions{
+			ProjectDirectory: dir,
+			InlineFiles: []client.ComposeFile{
+				{Content: []byte("services: {}"), Override: true},
+			},
+		},
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	ch, err := c.RunCommand("foo", "bar", nil, nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	<-ch
+
+	return cmd.fileArgs()
+}
+
+func TestInlineFileOverrideComposeFileEnv(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "compose.yaml"), []byte("services: {}"), 0600)
+
+	t.Setenv("COMPOSE_FILE", "base.yaml,/ci/compose.ci.yaml")
+	t.Setenv("COMPOSE_PATH_SEPARATOR", ",")
+
+	files := overrideFileArgs(t, dir)
+	expected := []string{filepath.Join(dir, "base.yaml"), "/ci/compose.ci.yaml", "-"}
+
+	if strings.Join(files, " ") != strings.Join(expected, " ") {
+		t.Errorf("expected the files in COMPOSE_FILE %v, got %v", expected, files)
+	}
+}
+
+func TestInlineFileOverrideComposeFileDotEnv(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "compose.yaml"), []byte("services: {}"), 0600)
+	os.WriteFile(filepath.Join(dir, ".env"), []byte("COMPOSE_FILE=\"base.yaml;ci.yaml\"\nCOMPOSE_PATH_SEPARATOR=;\n"), 0600)
+
+	t.Setenv("COMPOSE_FILE", "")
+	t.Setenv("COMPOSE_PATH_SEPARATOR", "")
+
+	files := overrideFileArgs(t, dir)
+	expected := []string{filepath.Join(dir, "base.yaml"), filepath.Join(dir, "ci.yaml"), "-"}
+
+	if strings.Join(files, " ") != strings.Join(expected, " ") {
+		t.Errorf("expected the files in COMPOSE_FILE from .env %v, got %v", expected, files)
+	}
+}
+
+func TestInlineFileWithStdin(t *testing.T) {
+	cmd := &inlineCmd{}
+
+	c := &client.ComposeClient{
+		GlobalOptions: &client.GlobalOptions{
+			InlineFiles: []client.ComposeFile{
+				{Content: []byte("services: {}")},
+			},
+		},
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	stdin := strings.NewReader("input")
+
+	ch, err := c.RunCommandWithStdin("foo", "bar", stdin, nil, nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	<-ch
+
+	if files := cmd.fileArgs(); len(files) != 1 || files[0] == "-" {
+		t.Errorf("expected a temporary file when the command has other input, got %v", files)
+	}
+
+	if cmd.stdin != stdin {
+		t.Error("expected the command's input to be passed on stdin")
+	}
+}
+
+func TestInlineFileStdinErrors(t *testing.T) {
+	cmd := &MockCmd{}
+
+	c := &client.ComposeClient{
+		GlobalOptions: &client.GlobalOptions{
+			InlineFileMode: client.InlineFileModeStdin,
+			InlineFiles: []client.ComposeFile{
+				{Content: []byte("services: {}")},
+			},
+		},
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	cmd.On("Run", mock.Anything)
+
+	if _, err := c.RunCommandWithStdin("foo", "bar", strings.NewReader("input"), nil, nil); err == nil {
+		t.Error("expected an error passing an inline file on stdin to a command with input")
+	}
+
+	_, err := c.RunCommand("foo", "bar", nil, nil, &client.GlobalOptions{
+		InlineFiles: []client.ComposeFile{
+			{Content: []byte("services: {}")},
+		},
+	})
+
+	if err == nil {
+		t.Error("expected an error passing two inline files on stdin")
+	}
+
+	if _, err := c.RunCommand("foo", "bar", nil, nil, &client.GlobalOptions{
+		InlineFiles:      []client.ComposeFile{{}},
+		InlineFilesMerge: client.ListMergeReplace,
+	}); err == nil {
+		t.Error("expected an error passing an inline file with no content")
+	}
+
+	cmd.AssertNotCalled(t, "Run", mock.Anything)
+}
+
+func TestProjectOptions(t *testing.T) {
+	cmd := &inlineCmd{}
+
+	c := &client.ComposeClient{
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	b := compose.NewBuilder("preview")
+	b.Service("web").Image("nginx")
+
+	project, err := b.Build()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts, err := client.ProjectOptions(project)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ch, err := c.RunCommand("config", "", nil, nil, opts)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	<-ch
+
+	expected := "name: preview\nservices:\n  web:\n    image: nginx\n"
+
+	if cmd.files["-"] != expected {
+		t.Errorf("expected %q, got %q", expected, cmd.files["-"])
+	}
+}
+
+// inlineQueryCmd is a queryCmd that records the inline file each command reads from stdin
+type inlineQueryCmd struct {
+	queryCmd
+	inputs *[]string
+}
+
+func (o *inlineQueryCmd) SetStdin(stdin io.Reader) {
+	content, _ := io.ReadAll(stdin)
+	*o.inputs = append(*o.inputs, string(content))
+}
+
+func TestInlineFileReaderSeveralCommands(t *testing.T) {
+	var inputs []string
+
+	content := "services: {web: {image: nginx:1.25}}"
+
+	c := &client.ComposeClient{
+		GlobalOptions: &client.GlobalOptions{
+			InlineFiles: []client.ComposeFile{
+				{Reader: strings.NewReader(content)},
+			},
+		},
+		NewCmd: func() client.Cmd {
+			return &inlineQueryCmd{
+				queryCmd: queryCmd{outputs: map[string]string{
+					"docker compose --file - config --format json":                         lockConfig,
+					"docker compose --file - config --format json --resolve-image-digests": lockResolvedConfig,
+				}},
+				inputs: &inputs,
+			}
+		},
+	}
+
+	if _, err := c.Lock(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.TestOverride(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(inputs) != 3 {
+		t.Fatalf("expected 3 commands, got %d", len(inputs))
+	}
+
+	for i, input := range inputs {
+		if input != content {
+			t.Errorf("expected command %d to read the inline file, got %q", i, input)
+		}
+	}
+}
@@ -0,0 +1,490 @@
+package client
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// VariableSource is where the value of a variable in the Compose file comes from
+type VariableSource string
+
+const (
+	// The variable is set in the environment docker compose runs in
+	VariableSourceEnvironment VariableSource = "environment"
+
+	// The variable is set in an env file, either `.env` in the project directory or one of GlobalOptions.EnvFiles
+	VariableSourceEnvFile VariableSource = "env_file"
+
+	// The variable isn't set, but every use of it has a default, e.g. `${TAG:-latest}`
+	VariableSourceDefault VariableSource = "default"
+
+	// The variable isn't set, and at least one use of it has no default, so compose substitutes an empty string, or
+	// fails if the variable is required, e.g. `${DB_PASSWORD:?}`
+	VariableSourceMissing VariableSource = "missing"
+)
+
+// VariableUsage is a use of a variable in the Compose file
+type VariableUsage struct {
+	// The path to the value, e.g. `services.db.environment.POSTGRES_PASSWORD`
+	Path string
+
+	// The value before interpolation, e.g. `postgres://app:${DB_PASSWORD}@db`
+	Raw string
+
+	// Whether the value is empty after interpolation, e.g. because the variable is unset and has no default. False if
+	// the interpolated config couldn't be resolved. The interpolated value isn't included, as it may contain secrets.
+	Empty bool
+
+	// The expression the variable is used in, e.g. `${TAG:-latest}`
+	Expression string
+
+	// Whether the expression gives a value when the variable is unset, e.g. `${TAG:-latest}` or `${TAG:+-dev}`
+	HasDefault bool
+
+	// The value the expression gives when the variable is unset
+	Default string
+
+	// Whether the expression fails when the variable is unset, e.g. `${DB_PASSWORD:?}`
+	Required bool
+}
+
+// Variable is a variable used in the Compose file
+type Variable struct {
+	Name   string
+	Source VariableSource
+
+	// The env file the variable is set in, when Source is VariableSourceEnvFile
+	EnvFile string
+
+	// Every use of the variable, sorted by path
+	Usages []VariableUsage
+}
+
+// InterpolationReport lists the variables used in the Compose file, and where their values come from.
+//
+// Values aren't included, as they are often secrets.
+type InterpolationReport struct {
+	// Sorted by name
+	Variables []Variable
+}
+
+// Missing returns the variables that aren't set and have no default
+func (r *InterpolationReport) Missing() []Variable {
+	var missing []Variable
+
+	for _, v := range r.Variables {
+		if v.Source == VariableSourceMissing {
+			missing = append(missing, v)
+		}
+	}
+
+	return missing
+}
+
+// Err returns a *MissingVariablesError if any variables are missing, e.g. to fail a CI job
+func (r *InterpolationReport) Err() error {
+	missing := r.Missing()
+
+	if len(missing) == 0 {
+		return nil
+	}
+
+	err := &MissingVariablesError{}
+
+	for _, v := range missing {
+		err.Variables = append(err.Variables, v.Name)
+	}
+
+	return err
+}
+
+// MissingVariablesError is returned by `InterpolationReport.Err` when variables used in the Compose file aren't set
+type MissingVariablesError struct {
+	Variables []string
+}
+
+func (e *MissingVariablesError) Error() string {
+	return fmt.Sprintf("variables are not set: %s", strings.Join(e.Variables, ", "))
+}
+
+// Reports the variables used in the Compose file, where each is used, and where its value comes from.
+//
+// Compose only warns about unset variables on stderr. The report compares the config with `--no-interpolate` to the
+// interpolated config, and looks each variable up in the environment and the env files.
+//
+// The environment is this process's, so the report assumes docker compose inherits it, as it does unless the Cmd
+// sets its own environment.
+//
+// If interpolation fails because a required variable is missing, the report is still returned, with no usages
+// reported Empty.
+func (c *ComposeClient) InterpolationReport(overrides ...*GlobalOptions) (*InterpolationReport, error) {
+	// The config is queried twice, so an inline file's Reader must be read once up front
+	if err := c.bufferInlineFiles(overrides...); err != nil {
+		return nil, err
+	}
+
+	raw, err := c.Config(&ConfigOptions{NoInterpolate: true}, overrides...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	interpolated, interpolateErr := c.Config(nil, overrides...)
+
+	envFiles, err := readEnvFiles(c.GlobalOptions.Merge(overrides...))
+
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := newInterpolationReport(raw, interpolated, envFiles)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if interpolateErr != nil && len(report.Missing()) == 0 {
+		return nil, interpolateErr
+	}
+
+	return report, nil
+}
+
+// envFile is the variables set in an env file
+type envFile struct {
+	path      string
+	variables map[string]string
+}
+
+// readEnvFiles reads the env files compose uses, in order of precedence: EnvFiles, the last taking precedence, or
+// `.env` in the project directory
+func readEnvFiles(opts *GlobalOptions) ([]envFile, error) {
+	paths := make([]string, 0, len(opts.EnvFiles))
+
+	for i := len(opts.EnvFiles) - 1; i >= 0; i-- {
+		paths = append(paths, opts.EnvFiles[i])
+	}
+
+	if len(paths) == 0 {
+		paths = append(paths, filepath.Join(envFileDir(opts), ".env"))
+	}
+
+	var files []envFile
+
+	for _, path := range paths {
+		variables, err := readEnvFile(path)
+
+		if os.IsNotExist(err) {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		files = append(files, envFile{path, variables})
+	}
+
+	return files, nil
+}
+
+// envFileDir returns the project directory compose reads `.env` from: ProjectDirectory, or the directory of the first
+// compose file, or the working directory if there is neither.
+func envFileDir(opts *GlobalOptions) string {
+	if opts.ProjectDirectory != "" {
+		return opts.ProjectDirectory
+	}
+
+	wd, _ := os.Getwd()
+
+	if len(opts.Files) > 0 {
+		// A file passed on stdin has no directory, so compose uses the working directory
+		if opts.Files[0] == "-" {
+			return wd
+		}
+
+		return filepath.Dir(opts.Files[0])
+	}
+
+	override := len(opts.InlineFiles) == 0
+
+	for _, file := range opts.InlineFiles {
+		if file.Override {
+			override = true
+		}
+	}
+
+	// Inline files that replace the project's files are resolved against the working directory, and otherwise the
+	// default compose files are used
+	if !override {
+		return wd
+	}
+
+	if files, err := defaultComposeFiles(wd); err == nil {
+		return filepath.Dir(files[0])
+	}
+
+	return wd
+}
+
+// readEnvFile returns the variables set in an env file, and their values
+func readEnvFile(path string) (map[string]string, error) {
+	f, err := os.Open(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer f.Close()
+
+	variables := map[string]string{}
+
+	scanner := bufio.NewScanner(f)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		name, value, _ := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		value = strings.TrimSpace(value)
+
+		// Quotes around the value aren't part of it
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+
+		if name = strings.TrimSpace(name); name != "" {
+			variables[name] = value
+		}
+	}
+
+	return variables, scanner.Err()
+}
+
+// newInterpolationReport finds the variables used in the raw config, and where their values come from
+func newInterpolationReport(raw, interpolated []byte, envFiles []envFile) (*InterpolationReport, error) {
+	var rawTree, interpolatedTree interface{}
+
+	if err := json.Unmarshal(raw, &rawTree); err != nil {
+		return nil, err
+	}
+
+	// The interpolated config is missing if interpolation failed
+	json.Unmarshal(interpolated, &interpolatedTree)
+
+	values := map[string]string{}
+	walkConfig(interpolatedTree, "", func(path, value string) {
+		values[path] = value
+	})
+
+	usages := map[string][]VariableUsage{}
+
+	walkConfig(rawTree, "", func(path, value string) {
+		interpolated, resolved := values[path]
+
+		for _, ref := range variableReferences(value) {
+			usages[ref.name] = append(usages[ref.name], VariableUsage{
+				Path:       path,
+				Raw:        value,
+				Empty:      resolved && interpolated == "",
+				Expression: ref.expression,
+				HasDefault: ref.hasDefault,
+				Default:    ref.defaultValue,
+				Required:   ref.required,
+			})
+		}
+	})
+
+	report := &InterpolationReport{}
+
+	for _, name := range sortedKeys(usages) {
+		v := Variable{Name: name, Usages: usages[name]}
+
+		if _, ok := os.LookupEnv(name); ok {
+			v.Source = VariableSourceEnvironment
+		} else if file := findEnvFile(envFiles, name); file != "" {
+			v.Source = VariableSourceEnvFile
+			v.EnvFile = file
+		} else {
+			v.Source = VariableSourceDefault
+
+			for _, usage := range v.Usages {
+				if !usage.HasDefault {
+					v.Source = VariableSourceMissing
+				}
+			}
+		}
+
+		report.Variables = append(report.Variables, v)
+	}
+
+	return report, nil
+}
+
+func findEnvFile(envFiles []envFile, name string) string {
+	for _, file := range envFiles {
+		if _, ok := file.variables[name]; ok {
+			return file.path
+		}
+	}
+
+	return ""
+}
+
+// walkConfig calls fn with the path and value of every scalar in the decoded JSON config, sorted by path,
+// e.g. `services.web.ports[0].published`
+func walkConfig(node interface{}, path string, fn func(path, value string)) {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		for _, key := range sortedKeys(n) {
+			child := key
+
+			if path != "" {
+				child = path + "." + key
+			}
+
+			walkConfig(n[key], child, fn)
+		}
+	case []interface{}:
+		for i, item := range n {
+			walkConfig(item, fmt.Sprintf("%s[%d]", path, i), fn)
+		}
+	case string:
+		fn(path, n)
+	case nil:
+	default:
+		fn(path, fmt.Sprint(n))
+	}
+}
+
+// variableReference is a variable used in a value, e.g. `${TAG:-latest}`
+type variableReference struct {
+	name         string
+	expression   string
+	hasDefault   bool
+	defaultValue string
+	required     bool
+}
+
+// variableReferences finds the variables used in a value, including those used in the defaults of others.
+// `$$` is an escaped `$`, so isn't a variable.
+func variableReferences(value string) []variableReference {
+	var refs []variableReference
+
+	for i := 0; i < len(value); i++ {
+		if value[i] != '$' || i+1 == len(value) {
+			continue
+		}
+
+		switch next := value[i+1]; {
+		case next == '$':
+			i++
+
+		case next == '{':
+			end := closingBrace(value, i+2)
+
+			if end == -1 {
+				return refs
+			}
+
+			ref, nested := parseBraced(value[i : end+1])
+
+			if ref.name != "" {
+				refs = append(refs, ref)
+			}
+
+			refs = append(refs, variableReferences(nested)...)
+			i = end
+
+		case isNameStart(next):
+			end := i + 2
+
+			for end < len(value) && isNameChar(value[end]) {
+				end++
+			}
+
+			refs = append(refs, variableReference{name: value[i+1 : end], expression: value[i:end]})
+			i = end - 1
+		}
+	}
+
+	return refs
+}
+
+// closingBrace returns the index of the brace closing the expression starting at start, allowing for nested expressions
+func closingBrace(value string, start int) int {
+	depth := 1
+
+	for i := start; i < len(value); i++ {
+		switch value[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+// parseBraced parses an expression like `${TAG:-latest}`, returning the reference and the text after the operator,
+// which may use other variables
+func parseBraced(expression string) (variableReference, string) {
+	body := expression[2 : len(expression)-1]
+	ref := variableReference{expression: expression}
+
+	end := 0
+
+	for end < len(body) && isNameChar(body[end]) {
+		end++
+	}
+
+	if end == 0 || !isNameStart(body[0]) {
+		return variableReference{}, ""
+	}
+
+	ref.name = body[:end]
+	rest := body[end:]
+
+	for _, op := range []string{":-", ":?", ":+", "-", "?", "+"} {
+		if !strings.HasPrefix(rest, op) {
+			continue
+		}
+
+		arg := rest[len(op):]
+
+		switch strings.TrimPrefix(op, ":") {
+		case "-":
+			ref.hasDefault = true
+			ref.defaultValue = arg
+		case "+":
+			// The alternative is only used when the variable is set, so an unset variable gives an empty string
+			ref.hasDefault = true
+		case "?":
+			ref.required = true
+		}
+
+		return ref, arg
+	}
+
+	return ref, ""
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isNameChar(c byte) bool {
+	return isNameStart(c) || (c >= '0' && c <= '9')
+}
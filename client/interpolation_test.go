@@ -0,0 +1,263 @@
+package client_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/harrim91/docker-compose-go/client"
+)
+
+const rawConfig = `{
+  "name": "app",
+  "services": {
+    "web": {
+      "image": "my/web:${TAG:-latest}",
+      "environment": {
+        "DATABASE_URL": "postgres://app:${DB_PASSWORD:?}@db/${DB_NAME}",
+        "PRICE": "$$5",
+        "SUFFIX": "${DEBUG:+-debug}"
+      },
+      "ports": [{"target": 80, "published": "${WEB_PORT:-${DEFAULT_PORT}}"}]
+    }
+  }
+}`
+
+const interpolatedConfig = `{
+  "name": "app",
+  "services": {
+    "web": {
+      "image": "my/web:latest",
+      "environment": {
+        "DATABASE_URL": "postgres://app:secret@db/app",
+        "PRICE": "$5",
+        "SUFFIX": ""
+      },
+      "ports": [{"target": 80, "published": 8080}]
+    }
+  }
+}`
+
+func TestInterpolationReport(t *testing.T) {
+	dir := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(dir, ".env"), []byte("# comment\nexport DB_NAME=app\nDEFAULT_PORT = 8080\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("DB_PASSWORD", "secret")
+
+	c := newQueryClient(map[string]string{
+		"docker compose --project-directory " + dir + " config --format json --no-interpolate": rawConfig,
+		"docker compose --project-directory " + dir + " config --format json":                  interpolatedConfig,
+	})
+
+	report, err := c.InterpolationReport(&client.GlobalOptions{ProjectDirectory: dir})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	databaseURL := "services.web.environment.DATABASE_URL"
+	published := "services.web.ports[0].published"
+	envFile := filepath.Join(dir, ".env")
+
+	expected := []client.Variable{
+		{Name: "DB_NAME", Source: client.VariableSourceEnvFile, EnvFile: envFile, Usages: []client.VariableUsage{
+			{Path: databaseURL, Raw: "postgres://app:${DB_PASSWORD:?}@db/${DB_NAME}", Expression: "${DB_NAME}"},
+		}},
+		{Name: "DB_PASSWORD", Source: client.VariableSourceEnvironment, Usages: []client.VariableUsage{
+			{Path: databaseURL, Raw: "postgres://app:${DB_PASSWORD:?}@db/${DB_NAME}", Expression: "${DB_PASSWORD:?}", Required: true},
+		}},
+		{Name: "DEBUG", Source: client.VariableSourceDefault, Usages: []client.VariableUsage{
+			{Path: "services.web.environment.SUFFIX", Raw: "${DEBUG:+-debug}", Empty: true, Expression: "${DEBUG:+-debug}", HasDefault: true},
+		}},
+		{Name: "DEFAULT_PORT", Source: client.VariableSourceEnvFile, EnvFile: envFile, Usages: []client.VariableUsage{
+			{Path: published, Raw: "${WEB_PORT:-${DEFAULT_PORT}}", Expression: "${DEFAULT_PORT}"},
+		}},
+		{Name: "TAG", Source: client.VariableSourceDefault, Usages: []client.VariableUsage{
+			{Path: "services.web.image", Raw: "my/web:${TAG:-latest}", Expression: "${TAG:-latest}", HasDefault: true, Default: "latest"},
+		}},
+		{Name: "WEB_PORT", Source: client.VariableSourceDefault, Usages: []client.VariableUsage{
+			{Path: published, Raw: "${WEB_PORT:-${DEFAULT_PORT}}", Expression: "${WEB_PORT:-${DEFAULT_PORT}}", HasDefault: true, Default: "${DEFAULT_PORT}"},
+		}},
+	}
+
+	if !reflect.DeepEqual(report.Variables, expected) {
+		t.Errorf("expected:\n%+v\ngot:\n%+v", expected, report.Variables)
+	}
+
+	if err := report.Err(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestInterpolationReportMissing(t *testing.T) {
+	dir := t.TempDir()
+
+	c := newQueryClient(map[string]string{
+		"docker compose --project-directory " + dir + " config --format json --no-interpolate": rawConfig,
+	})
+
+	// The interpolated config fails, as DB_PASSWORD is required
+	report, err := c.InterpolationReport(&client.GlobalOptions{ProjectDirectory: dir})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var missingErr *client.MissingVariablesError
+
+	if err := report.Err(); !errors.As(err, &missingErr) {
+		t.Fatalf("expected a MissingVariablesError, got %v", err)
+	}
+
+	expected := []string{"DB_NAME", "DB_PASSWORD", "DEFAULT_PORT"}
+
+	if !reflect.DeepEqual(missingErr.Variables, expected) {
+		t.Errorf("expected %v, got %v", expected, missingErr.Variables)
+	}
+
+	if missingErr.Error() != "variables are not set: DB_NAME, DB_PASSWORD, DEFAULT_PORT" {
+		t.Errorf("unexpected error: %s", missingErr)
+	}
+
+	if usage := report.Variables[2].Usages[0]; usage.Empty {
+		t.Errorf("expected %s not to be reported empty when interpolation failed", usage.Path)
+	}
+}
+
+// chdir changes the working directory for the rest of the test
+func chdir(t *testing.T, dir string) {
+	wd, err := os.Getwd()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		os.Chdir(wd)
+	})
+}
+
+// inlineEnvFileSource returns the env file DB_NAME is reported to be set in
+func inlineEnvFileSource(t *testing.T, c *client.ComposeClient, opts *client.GlobalOptions) string {
+	t.Helper()
+
+	report, err := c.InterpolationReport(opts)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, v := range report.Variables {
+		if v.Name == "DB_NAME" {
+			return v.EnvFile
+		}
+	}
+
+	return ""
+}
+
+func TestInterpolationReportInlineFile(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, ".env"), []byte("DB_NAME=app\n"), 0600)
+
+	chdir(t, dir)
+
+	c := newQueryClient(map[string]string{
+		"docker compose --file - config --format json --no-interpolate": rawConfig,
+		"docker compose --file - config --format json":                  interpolatedConfig,
+	})
+
+	opts := &client.GlobalOptions{
+		InlineFiles: []client.ComposeFile{{Content: []byte(rawConfig)}},
+	}
+
+	if envFile, expected := inlineEnvFileSource(t, c, opts), filepath.Join(dir, ".env"); envFile != expected {
+		t.Errorf("expected DB_NAME from %s in the working directory, got %q", expected, envFile)
+	}
+}
+
+func TestInterpolationReportInlineFileReader(t *testing.T) {
+	chdir(t, t.TempDir())
+
+	var inputs []string
+
+	c := &client.ComposeClient{
+		NewCmd: func() client.Cmd {
+			return &inlineQueryCmd{
+				queryCmd: queryCmd{outputs: map[string]string{
+					"docker compose --file - config --format json --no-interpolate": rawConfig,
+					"docker compose --file - config --format json":                  interpolatedConfig,
+				}},
+				inputs: &inputs,
+			}
+		},
+	}
+
+	_, err := c.InterpolationReport(&client.GlobalOptions{
+		InlineFiles: []client.ComposeFile{{Reader: strings.NewReader(rawConfig)}},
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(inputs) != 2 || inputs[0] != rawConfig || inputs[1] != rawConfig {
+		t.Errorf("expected both commands to read the inline file, got %q", inputs)
+	}
+}
+
+func TestInterpolationReportInlineOverride(t *testing.T) {
+	dir := t.TempDir()
+	sub := filepath.Join(dir, "sub")
+	os.Mkdir(sub, 0700)
+	os.WriteFile(filepath.Join(dir, "compose.yaml"), []byte("services: {}"), 0600)
+	os.WriteFile(filepath.Join(dir, ".env"), []byte("DB_NAME=app\n"), 0600)
+
+	chdir(t, sub)
+
+	files := "--file " + filepath.Join(dir, "compose.yaml") + " --file -"
+
+	c := newQueryClient(map[string]string{
+		"docker compose " + files + " config --format json --no-interpolate": rawConfig,
+		"docker compose " + files + " config --format json":                  interpolatedConfig,
+	})
+
+	opts := &client.GlobalOptions{
+		InlineFiles: []client.ComposeFile{{Content: []byte("services: {}"), Override: true}},
+	}
+
+	if envFile, expected := inlineEnvFileSource(t, c, opts), filepath.Join(dir, ".env"); envFile != expected {
+		t.Errorf("expected DB_NAME from %s next to the default compose file, got %q", expected, envFile)
+	}
+}
+
+func TestInterpolationReportInlineProjectDirectory(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, ".env"), []byte("DB_NAME=app\n"), 0600)
+
+	chdir(t, t.TempDir())
+
+	c := newQueryClient(map[string]string{
+		"docker compose --file - --project-directory " + dir + " config --format json --no-interpolate": rawConfig,
+		"docker compose --file - --project-directory " + dir + " config --format json":                  interpolatedConfig,
+	})
+
+	opts := &client.GlobalOptions{
+		ProjectDirectory: dir,
+		InlineFiles:      []client.ComposeFile{{Content: []byte(rawConfig)}},
+	}
+
+	if envFile, expected := inlineEnvFileSource(t, c, opts), filepath.Join(dir, ".env"); envFile != expected {
+		t.Errorf("expected DB_NAME from %s in the project directory, got %q", expected, envFile)
+	}
+}
@@ -0,0 +1,349 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/harrim91/docker-compose-go/compose"
+)
+
+// The file the lock is conventionally written to, next to the compose file
+const LockFileName = "compose.lock"
+
+const lockFileVersion = 1
+
+// LockFile pins the image of each service to a digest, so deploys are reproducible. It is created with `Lock`.
+//
+// The lock is written as JSON, e.g.
+//
+//	{
+//	  "version": 1,
+//	  "services": {
+//	    "web": {
+//	      "image": "nginx:1.25",
+//	      "digest": "sha256:6db391d1c0cfb30588ba0bf72ea999404f2764febf0f1f196acd5867ac7efa7e"
+//	    }
+//	  }
+//	}
+type LockFile struct {
+	Version  int                    `json:"version"`
+	Services map[string]LockedImage `json:"services"`
+}
+
+// LockedImage is the image a service uses, and the digest it was resolved to
+type LockedImage struct {
+	// The image, as written in the Compose file
+	Image string `json:"image"`
+
+	// The digest of the image, e.g. `sha256:6db3...`
+	Digest string `json:"digest"`
+}
+
+// Reference returns the image pinned to its digest, e.g. `nginx:1.25@sha256:6db3...`
+func (i LockedImage) Reference() string {
+	image, _, _ := strings.Cut(i.Image, "@")
+
+	return image + "@" + i.Digest
+}
+
+// ReadLockFile reads a lock file written by `LockFile.Write`
+func ReadLockFile(path string) (*LockFile, error) {
+	b, err := os.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseLockFile(b)
+}
+
+// ParseLockFile parses the content of a lock file
+func ParseLockFile(b []byte) (*LockFile, error) {
+	var lock LockFile
+
+	if err := json.Unmarshal(b, &lock); err != nil {
+		return nil, err
+	}
+
+	if lock.Version != lockFileVersion {
+		return nil, fmt.Errorf("unsupported lock file version %d", lock.Version)
+	}
+
+	if lock.Services == nil {
+		lock.Services = map[string]LockedImage{}
+	}
+
+	return &lock, nil
+}
+
+// Write writes the lock to the file at path, e.g. LockFileName
+func (l *LockFile) Write(path string) error {
+	b, err := json.MarshalIndent(l, "", "  ")
+
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, append(b, '\n'), 0644)
+}
+
+// Override returns a compose override file that pins the image of each locked service to its digest
+func (l *LockFile) Override() ([]byte, error) {
+	p := &compose.Project{Services: map[string]*compose.Service{}}
+
+	for service, image := range l.Services {
+		p.Services[service] = &compose.Service{Image: image.Reference()}
+	}
+
+	return p.YAML()
+}
+
+// Options returns options that apply the override file, pinning images to their digests, on top of the project's
+// compose files. These can be passed as an override to any command, e.g. `c.Up(opts, w, lockOptions)`.
+func (l *LockFile) Options() (*GlobalOptions, error) {
+	content, err := l.Override()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &GlobalOptions{
+		InlineFiles: []ComposeFile{
+			{Name: "compose.lock.yaml", Content: content, Override: true},
+		},
+	}, nil
+}
+
+// Resolves the digest of each service's image with `docker compose config --resolve-image-digests`, returning a lock
+// that can be written to LockFileName.
+//
+// Services without an image, which are only built, aren't locked. Resolving digests requires the images to be in a registry.
+func (c *ComposeClient) Lock(overrides ...*GlobalOptions) (*LockFile, error) {
+	images, err := c.resolveImages(overrides...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	lock := &LockFile{Version: lockFileVersion, Services: map[string]LockedImage{}}
+
+	for service, image := range images {
+		if image.Digest != "" {
+			lock.Services[service] = image
+		}
+	}
+
+	return lock, nil
+}
+
+// resolveImages returns the image and digest of each service with an image. The digest is empty if it couldn't be resolved.
+func (c *ComposeClient) resolveImages(overrides ...*GlobalOptions) (map[string]LockedImage, error) {
+	// The config is resolved twice, so an inline file's Reader must be read once up front
+	if err := c.bufferInlineFiles(overrides...); err != nil {
+		return nil, err
+	}
+
+	project, err := c.ConfigProject(nil, overrides...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	resolved, err := c.ConfigProject(&ConfigOptions{ResolveImageDigests: true}, overrides...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	images := map[string]LockedImage{}
+
+	for name, s := range project.Services {
+		if s == nil || s.Image == "" {
+			continue
+		}
+
+		image := LockedImage{Image: s.Image}
+
+		if r, ok := resolved.Services[name]; ok && r != nil {
+			_, image.Digest, _ = strings.Cut(r.Image, "@")
+		}
+
+		images[name] = image
+	}
+
+	return images, nil
+}
+
+// LockMismatchKind is how a service differs from the lock
+type LockMismatchKind string
+
+const (
+	// The service uses a different image to the one locked
+	LockImageChanged LockMismatchKind = "image_changed"
+
+	// The image resolves to a different digest, e.g. because its tag was pushed again
+	LockDigestChanged LockMismatchKind = "digest_changed"
+
+	// The service isn't in the lock
+	LockUnlocked LockMismatchKind = "unlocked"
+
+	// The locked service is no longer in the config
+	LockRemoved LockMismatchKind = "removed"
+
+	// The container wasn't created from an image pinned to a digest, so which digest it runs can't be verified.
+	// Deploy with `LockFile.Options` to pin images.
+	LockUnpinned LockMismatchKind = "unpinned"
+)
+
+// LockMismatch is a service that doesn't match the lock
+type LockMismatch struct {
+	Service string
+	Kind    LockMismatchKind
+
+	// The locked image reference, e.g. `nginx:1.25@sha256:6db3...`. Empty if the service isn't locked.
+	Locked string
+
+	// The image reference in the config or container. Empty if the service was removed.
+	Actual string
+
+	// The container, when verifying a running stack
+	Container string
+}
+
+func (m LockMismatch) String() string {
+	if m.Container != "" {
+		return fmt.Sprintf("%s (%s): %s, locked %q, running %q", m.Service, m.Container, m.Kind, m.Locked, m.Actual)
+	}
+
+	return fmt.Sprintf("%s: %s, locked %q, config %q", m.Service, m.Kind, m.Locked, m.Actual)
+}
+
+// Resolves the digest of each service's image in the current config, and compares them to the lock.
+//
+// Returns the services that don't match, sorted by service, or nil if the config matches the lock.
+func (c *ComposeClient) VerifyLock(lock *LockFile, overrides ...*GlobalOptions) ([]LockMismatch, error) {
+	images, err := c.resolveImages(overrides...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var mismatches []LockMismatch
+
+	for _, service := range sortedKeys(images) {
+		image := images[service]
+		locked, ok := lock.Services[service]
+
+		mismatch := LockMismatch{Service: service, Actual: image.Image}
+
+		if image.Digest != "" {
+			mismatch.Actual = image.Reference()
+		}
+
+		switch {
+		case !ok:
+			mismatch.Kind = LockUnlocked
+		case stripDigest(image.Image) != stripDigest(locked.Image):
+			mismatch.Kind = LockImageChanged
+		case image.Digest != locked.Digest:
+			mismatch.Kind = LockDigestChanged
+		default:
+			continue
+		}
+
+		if ok {
+			mismatch.Locked = locked.Reference()
+		}
+
+		mismatches = append(mismatches, mismatch)
+	}
+
+	for _, service := range sortedKeys(lock.Services) {
+		if _, ok := images[service]; !ok {
+			mismatches = append(mismatches, LockMismatch{
+				Service: service,
+				Kind:    LockRemoved,
+				Locked:  lock.Services[service].Reference(),
+			})
+		}
+	}
+
+	sortMismatches(mismatches)
+
+	return mismatches, nil
+}
+
+// Compares the images of the project's containers, from `docker compose ps`, to the lock.
+//
+// Containers are only verified if they were created from an image pinned to a digest, e.g. when deployed with
+// `LockFile.Options`. Others are reported as LockUnpinned.
+//
+// Returns the containers that don't match, sorted by service, or nil if every container matches the lock.
+func (c *ComposeClient) VerifyRunningLock(lock *LockFile, overrides ...*GlobalOptions) ([]LockMismatch, error) {
+	ps, err := c.RunQuery("ps", "--format json", overrides...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	containers, err := decodePs(ps)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var mismatches []LockMismatch
+
+	for _, container := range containers {
+		locked, ok := lock.Services[container.Service]
+
+		mismatch := LockMismatch{
+			Service:   container.Service,
+			Actual:    container.Image,
+			Container: container.Name,
+		}
+
+		_, digest, pinned := strings.Cut(container.Image, "@")
+
+		switch {
+		case !ok:
+			mismatch.Kind = LockUnlocked
+		case stripDigest(container.Image) != stripDigest(locked.Image):
+			mismatch.Kind = LockImageChanged
+		case !pinned:
+			mismatch.Kind = LockUnpinned
+		case digest != locked.Digest:
+			mismatch.Kind = LockDigestChanged
+		default:
+			continue
+		}
+
+		if ok {
+			mismatch.Locked = locked.Reference()
+		}
+
+		mismatches = append(mismatches, mismatch)
+	}
+
+	sortMismatches(mismatches)
+
+	return mismatches, nil
+}
+
+func stripDigest(image string) string {
+	image, _, _ = strings.Cut(image, "@")
+	return image
+}
+
+func sortMismatches(mismatches []LockMismatch) {
+	sort.SliceStable(mismatches, func(i, j int) bool {
+		if mismatches[i].Service != mismatches[j].Service {
+			return mismatches[i].Service < mismatches[j].Service
+		}
+
+		return mismatches[i].Container < mismatches[j].Container
+	})
+}
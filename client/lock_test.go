@@ -0,0 +1,203 @@
+package client_test
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/harrim91/docker-compose-go/client"
+)
+
+const (
+	lockConfig = `{"name":"app","services":{
+		"web":{"image":"nginx:1.25"},
+		"db":{"image":"postgres:16"},
+		"app":{"build":{"context":"."}}
+	}}`
+
+	lockResolvedConfig = `{"name":"app","services":{
+		"web":{"image":"nginx:1.25@sha256:aaa"},
+		"db":{"image":"postgres:16@sha256:bbb"},
+		"app":{"build":{"context":"."}}
+	}}`
+)
+
+func newLockClient(config, resolved string) *client.ComposeClient {
+	return newQueryClient(map[string]string{
+		"docker compose config --format json":                         config,
+		"docker compose config --format json --resolve-image-digests": resolved,
+	})
+}
+
+func TestLock(t *testing.T) {
+	lock, err := newLockClient(lockConfig, lockResolvedConfig).Lock()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &client.LockFile{
+		Version: 1,
+		Services: map[string]client.LockedImage{
+			"db":  {Image: "postgres:16", Digest: "sha256:bbb"},
+			"web": {Image: "nginx:1.25", Digest: "sha256:aaa"},
+		},
+	}
+
+	if !reflect.DeepEqual(lock, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, lock)
+	}
+
+	path := filepath.Join(t.TempDir(), client.LockFileName)
+
+	if err := lock.Write(path); err != nil {
+		t.Fatal(err)
+	}
+
+	read, err := client.ReadLockFile(path)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(read, expected) {
+		t.Errorf("expected %+v, got %+v", expected, read)
+	}
+}
+
+func TestParseLockFileVersion(t *testing.T) {
+	if _, err := client.ParseLockFile([]byte(`{"version":2,"services":{}}`)); err == nil {
+		t.Error("expected an error for an unsupported version")
+	}
+}
+
+func TestLockOverride(t *testing.T) {
+	lock := &client.LockFile{
+		Version: 1,
+		Services: map[string]client.LockedImage{
+			"web": {Image: "nginx:1.25", Digest: "sha256:aaa"},
+		},
+	}
+
+	override, err := lock.Override()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "services:\n  web:\n    image: nginx:1.25@sha256:aaa\n"
+
+	if string(override) != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, override)
+	}
+
+	opts, err := lock.Options()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(opts.InlineFiles) != 1 || !opts.InlineFiles[0].Override || string(opts.InlineFiles[0].Content) != expected {
+		t.Errorf("expected the override as an inline override file, got %+v", opts.InlineFiles)
+	}
+}
+
+func TestVerifyLock(t *testing.T) {
+	lock := &client.LockFile{
+		Version: 1,
+		Services: map[string]client.LockedImage{
+			"web":    {Image: "nginx:1.24", Digest: "sha256:aaa"},
+			"db":     {Image: "postgres:16", Digest: "sha256:old"},
+			"legacy": {Image: "redis:7", Digest: "sha256:ccc"},
+		},
+	}
+
+	config := `{"services":{
+		"web":{"image":"nginx:1.25"},
+		"db":{"image":"postgres:16"},
+		"cache":{"image":"memcached:1.6"}
+	}}`
+
+	resolved := `{"services":{
+		"web":{"image":"nginx:1.25@sha256:aaa"},
+		"db":{"image":"postgres:16@sha256:bbb"},
+		"cache":{"image":"memcached:1.6@sha256:ddd"}
+	}}`
+
+	mismatches, err := newLockClient(config, resolved).VerifyLock(lock)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []client.LockMismatch{
+		{Service: "cache", Kind: client.LockUnlocked, Actual: "memcached:1.6@sha256:ddd"},
+		{Service: "db", Kind: client.LockDigestChanged, Locked: "postgres:16@sha256:old", Actual: "postgres:16@sha256:bbb"},
+		{Service: "legacy", Kind: client.LockRemoved, Locked: "redis:7@sha256:ccc"},
+		{Service: "web", Kind: client.LockImageChanged, Locked: "nginx:1.24@sha256:aaa", Actual: "nginx:1.25@sha256:aaa"},
+	}
+
+	if !reflect.DeepEqual(mismatches, expected) {
+		t.Errorf("expected %+v, got %+v", expected, mismatches)
+	}
+}
+
+func TestVerifyLockMatches(t *testing.T) {
+	c := newLockClient(lockConfig, lockResolvedConfig)
+
+	lock, err := c.Lock()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mismatches, err := c.VerifyLock(lock)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if mismatches != nil {
+		t.Errorf("expected no mismatches, got %+v", mismatches)
+	}
+}
+
+func TestVerifyRunningLock(t *testing.T) {
+	lock := &client.LockFile{
+		Version: 1,
+		Services: map[string]client.LockedImage{
+			"web": {Image: "nginx:1.25", Digest: "sha256:aaa"},
+			"db":  {Image: "postgres:16", Digest: "sha256:bbb"},
+		},
+	}
+
+	ps := `{"Name":"app-web-1","Service":"web","Image":"nginx:1.25@sha256:aaa"}
+{"Name":"app-web-2","Service":"web","Image":"nginx:1.25@sha256:old"}
+{"Name":"app-db-1","Service":"db","Image":"postgres:16"}
+{"Name":"app-cache-1","Service":"cache","Image":"redis:7"}
+`
+
+	c := newQueryClient(map[string]string{
+		"docker compose ps --format json": ps,
+	})
+
+	mismatches, err := c.VerifyRunningLock(lock)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []client.LockMismatch{
+		{Service: "cache", Kind: client.LockUnlocked, Actual: "redis:7", Container: "app-cache-1"},
+		{Service: "db", Kind: client.LockUnpinned, Locked: "postgres:16@sha256:bbb", Actual: "postgres:16", Container: "app-db-1"},
+		{Service: "web", Kind: client.LockDigestChanged, Locked: "nginx:1.25@sha256:aaa", Actual: "nginx:1.25@sha256:old", Container: "app-web-2"},
+	}
+
+	if !reflect.DeepEqual(mismatches, expected) {
+		t.Errorf("expected %+v, got %+v", expected, mismatches)
+	}
+
+	if s := expected[2].String(); s != `web (app-web-2): digest_changed, locked "nginx:1.25@sha256:aaa", running "nginx:1.25@sha256:old"` {
+		t.Errorf("unexpected string %s", s)
+	}
+}
@@ -0,0 +1,443 @@
+package client
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ManagedProject is a compose project tracked by a Manager
+type ManagedProject struct {
+	// The compose project name. Passed to every command as `--project-name`.
+	Name string
+
+	// Options applied to every command for this project, on top of the client's GlobalOptions.
+	Options *GlobalOptions
+
+	// Arbitrary labels used to find projects, e.g. the branch or CI job that created them.
+	Labels map[string]string
+
+	// When the project was added to the Manager, or when its newest container was created if it was found by Discover.
+	Created time.Time
+
+	mu       sync.Mutex
+	lastUsed time.Time
+
+	// Closed once the last operation queued on the project has completed. Each operation waits for the one before it,
+	// so operations run one at a time, in the order they were queued.
+	tail chan struct{}
+}
+
+// LastUsed returns when an operation on the project last completed, or when it was created if none has
+func (p *ManagedProject) LastUsed() time.Time {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.lastUsed
+}
+
+// enqueue queues an operation on the project, returning the channel to wait on before it runs, which is nil if the
+// queue is empty, and the channel to close once it has completed
+func (p *ManagedProject) enqueue() (<-chan struct{}, chan struct{}) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	prev := p.tail
+	done := make(chan struct{})
+	p.tail = done
+
+	return prev, done
+}
+
+// ProjectFilter selects managed projects. Empty fields match every project.
+type ProjectFilter struct {
+	// Only match projects that have all of these labels.
+	Labels map[string]string
+
+	// Only match projects that haven't been used for at least this long.
+	UnusedFor time.Duration
+}
+
+func (f *ProjectFilter) matches(project *ManagedProject, now time.Time) bool {
+	if f == nil {
+		return true
+	}
+
+	for key, value := range f.Labels {
+		if v, ok := project.Labels[key]; !ok || v != value {
+			return false
+		}
+	}
+
+	return f.UnusedFor == 0 || now.Sub(project.LastUsed()) >= f.UnusedFor
+}
+
+// ManagerOptions configures a Manager
+type ManagerOptions struct {
+	// The maximum number of expensive operations (build, pull) to run at once across all projects. 0 means unlimited.
+	MaxConcurrentBuilds int
+}
+
+// ManagerError reports the projects that failed during an operation on several projects
+type ManagerError struct {
+	Errors map[string]error
+}
+
+func (e *ManagerError) Error() string {
+	names := make([]string, 0, len(e.Errors))
+
+	for name := range e.Errors {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	messages := make([]string, 0, len(names))
+
+	for _, name := range names {
+		messages = append(messages, fmt.Sprintf("%s: %v", name, e.Errors[name]))
+	}
+
+	return strings.Join(messages, "; ")
+}
+
+// Manager runs commands against many named compose projects using a single ComposeClient.
+//
+// Operations on the same project run one at a time, in the order they were started, and expensive operations are
+// limited across all projects. It should be created with `NewManager`.
+type Manager struct {
+	client    *ComposeClient
+	mu        sync.Mutex
+	projects  map[string]*ManagedProject
+	expensive chan struct{}
+}
+
+// NewManager returns a new Manager that runs commands with the given client
+func NewManager(client *ComposeClient, opts *ManagerOptions) *Manager {
+	m := &Manager{
+		client:   client,
+		projects: map[string]*ManagedProject{},
+	}
+
+	if opts != nil && opts.MaxConcurrentBuilds > 0 {
+		m.expensive = make(chan struct{}, opts.MaxConcurrentBuilds)
+	}
+
+	return m
+}
+
+// Add starts tracking a project. It returns an error if a project with the same name is already tracked.
+func (m *Manager) Add(name string, opts *GlobalOptions, labels map[string]string) (*ManagedProject, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.projects[name]; ok {
+		return nil, fmt.Errorf("project %q is already managed", name)
+	}
+
+	now := time.Now()
+
+	project := &ManagedProject{
+		Name:     name,
+		Options:  opts,
+		Labels:   labels,
+		Created:  now,
+		lastUsed: now,
+	}
+
+	m.projects[name] = project
+
+	return project, nil
+}
+
+// Remove stops tracking a project, without tearing it down.
+func (m *Manager) Remove(name string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.projects, name)
+}
+
+// Get returns the named project, or nil if it isn't tracked.
+func (m *Manager) Get(name string) *ManagedProject {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.projects[name]
+}
+
+// List returns the tracked projects matching the filter, sorted by name. A nil filter matches every project.
+//
+// Only projects added to this Manager are listed. Call Discover first to include projects from earlier runs.
+func (m *Manager) List(filter *ProjectFilter) []*ManagedProject {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	projects := []*ManagedProject{}
+
+	for _, project := range m.projects {
+		if filter.matches(project, now) {
+			projects = append(projects, project)
+		}
+	}
+
+	sort.Slice(projects, func(i, j int) bool {
+		return projects[i].Name < projects[j].Name
+	})
+
+	return projects
+}
+
+// Run runs a command against the named project once every operation started on the project before it has completed.
+//
+// The command is given the project's overrides, which must be passed on to the client. If expensive is true, the command also waits for a free slot within MaxConcurrentBuilds.
+//
+// The returned channel will emit a single error and then close once the command has completed.
+func (m *Manager) Run(name string, expensive bool, command func(overrides ...*GlobalOptions) (<-chan error, error)) (<-chan error, error) {
+	project := m.Get(name)
+
+	if project == nil {
+		return nil, fmt.Errorf("project %q is not managed", name)
+	}
+
+	// Queued before returning, so operations started one after the other run in that order
+	prev, done := project.enqueue()
+	ch := make(chan error)
+
+	go func() {
+		defer close(ch)
+
+		// Release the project before emitting, so an unread result never blocks the next operation
+		ch <- m.run(project, prev, done, expensive, command)
+	}()
+
+	return ch, nil
+}
+
+func (m *Manager) run(project *ManagedProject, prev <-chan struct{}, done chan struct{}, expensive bool, command func(overrides ...*GlobalOptions) (<-chan error, error)) error {
+	defer close(done)
+
+	if prev != nil {
+		<-prev
+	}
+
+	if expensive && m.expensive != nil {
+		m.expensive <- struct{}{}
+		defer func() { <-m.expensive }()
+	}
+
+	cmdCh, err := command(project.overrides()...)
+
+	if err == nil {
+		err = <-cmdCh
+	}
+
+	project.mu.Lock()
+	project.lastUsed = time.Now()
+	project.mu.Unlock()
+
+	return err
+}
+
+func (p *ManagedProject) overrides() []*GlobalOptions {
+	return []*GlobalOptions{
+		p.Options,
+		{ProjectName: p.Name},
+	}
+}
+
+// Build runs `docker compose build` for the named project. Builds count towards MaxConcurrentBuilds.
+func (m *Manager) Build(name string, opts *BuildOptions, w io.Writer) (<-chan error, error) {
+	return m.Run(name, true, func(overrides ...*GlobalOptions) (<-chan error, error) {
+		return m.client.Build(opts, w, overrides...)
+	})
+}
+
+// Pull runs `docker compose pull` for the named project. Pulls count towards MaxConcurrentBuilds.
+func (m *Manager) Pull(name string, services []string, w io.Writer) (<-chan error, error) {
+	return m.Run(name, true, func(overrides ...*GlobalOptions) (<-chan error, error) {
+		return m.client.RunCommand("pull", strings.Join(services, " "), nil, w, overrides...)
+	})
+}
+
+// Up runs `docker compose up` for the named project. If images are built or always pulled, it counts towards MaxConcurrentBuilds.
+func (m *Manager) Up(name string, opts *UpOptions, w io.Writer) (<-chan error, error) {
+	expensive := opts != nil && (opts.Build || opts.Pull == PullPolicyFlagAlways || opts.Pull == PullPolicyFlagBuild)
+
+	return m.Run(name, expensive, func(overrides ...*GlobalOptions) (<-chan error, error) {
+		return m.client.Up(opts, w, overrides...)
+	})
+}
+
+// Down runs `docker compose down` for the named project.
+func (m *Manager) Down(name string, opts *DownOptions, w io.Writer) (<-chan error, error) {
+	return m.Run(name, false, func(overrides ...*GlobalOptions) (<-chan error, error) {
+		return m.client.Down(opts, w, overrides...)
+	})
+}
+
+// DownAll runs `docker compose down` for every tracked project matching the filter, and stops tracking those that were torn down.
+//
+// A nil filter matches every project. To tear down stale projects, set the filter's UnusedFor and Labels, and call
+// Discover first to include projects left by earlier runs.
+//
+// The returned channel will emit a single *ManagerError (or nil) and then close once every project has been torn down.
+func (m *Manager) DownAll(filter *ProjectFilter, opts *DownOptions, w io.Writer) <-chan error {
+	projects := m.List(filter)
+	ch := make(chan error)
+
+	go func() {
+		defer close(ch)
+
+		var (
+			wg   sync.WaitGroup
+			mu   sync.Mutex
+			errs = map[string]error{}
+		)
+
+		for _, project := range projects {
+			wg.Add(1)
+
+			go func(name string) {
+				defer wg.Done()
+
+				downCh, err := m.Down(name, opts, w)
+
+				if err == nil {
+					err = <-downCh
+				}
+
+				if err != nil {
+					mu.Lock()
+					errs[name] = err
+					mu.Unlock()
+					return
+				}
+
+				m.Remove(name)
+			}(project.Name)
+		}
+
+		wg.Wait()
+
+		if len(errs) > 0 {
+			ch <- &ManagerError{Errors: errs}
+			return
+		}
+
+		ch <- nil
+	}()
+
+	return ch
+}
+
+// composeLabelPrefix prefixes the labels compose sets on the containers it creates
+const composeLabelPrefix = "com.docker.compose."
+
+// Discover finds the compose projects on the Docker host that aren't tracked, e.g. stacks left by earlier CI runs,
+// and starts tracking them so that List and DownAll can find them.
+//
+// Projects are found with `docker compose ls`. A discovered project's Labels are the labels every one of its
+// containers has, e.g. those set in the Compose file, other than the ones compose sets. It was last used when its
+// newest container was created.
+//
+// Returns the projects that were added, sorted by name.
+func (m *Manager) Discover() ([]*ManagedProject, error) {
+	out, err := m.client.RunQuery("ls", "--all --format json")
+
+	if err != nil {
+		return nil, err
+	}
+
+	var ls []struct {
+		Name string
+	}
+
+	if err := json.Unmarshal(out, &ls); err != nil {
+		return nil, err
+	}
+
+	added := []*ManagedProject{}
+
+	for _, entry := range ls {
+		if m.Get(entry.Name) != nil {
+			continue
+		}
+
+		project, err := m.discoverProject(entry.Name)
+
+		if err != nil {
+			return nil, err
+		}
+
+		m.mu.Lock()
+
+		if _, ok := m.projects[project.Name]; !ok {
+			m.projects[project.Name] = project
+			added = append(added, project)
+		}
+
+		m.mu.Unlock()
+	}
+
+	sort.Slice(added, func(i, j int) bool {
+		return added[i].Name < added[j].Name
+	})
+
+	return added, nil
+}
+
+// discoverProject reads the labels and creation time of a project's containers with `docker compose ps`
+func (m *Manager) discoverProject(name string) (*ManagedProject, error) {
+	out, err := m.client.RunQuery("ps", "--all --format json", &GlobalOptions{ProjectName: name})
+
+	if err != nil {
+		return nil, err
+	}
+
+	containers, err := decodePs(out)
+
+	if err != nil {
+		return nil, err
+	}
+
+	project := &ManagedProject{Name: name}
+
+	for i, container := range containers {
+		labels := container.labels()
+
+		if i == 0 {
+			project.Labels = map[string]string{}
+
+			for key, value := range labels {
+				if !strings.HasPrefix(key, composeLabelPrefix) {
+					project.Labels[key] = value
+				}
+			}
+		}
+
+		for key, value := range project.Labels {
+			if v, ok := labels[key]; !ok || v != value {
+				delete(project.Labels, key)
+			}
+		}
+
+		if created := container.created(); created.After(project.Created) {
+			project.Created = created
+		}
+	}
+
+	// A project whose age is unknown is never treated as stale
+	if project.Created.IsZero() {
+		project.Created = time.Now()
+	}
+
+	project.lastUsed = project.Created
+
+	return project, nil
+}
@@ -0,0 +1,394 @@
+package client_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/harrim91/docker-compose-go/client"
+)
+
+// blockingCmd records the commands it runs, and holds each one open until released
+type blockingCmd struct {
+	running  *int32
+	peak     *int32
+	release  chan struct{}
+	mu       *sync.Mutex
+	commands *[]string
+}
+
+func (o *blockingCmd) SetStdin(stdin io.Reader) {}
+
+func (o *blockingCmd) SetStdout(stdout io.Writer) {}
+
+func (o *blockingCmd) SetStderr(stderr io.Writer) {}
+
+func (o *blockingCmd) Run(cmd string) (<-chan error, error) {
+	o.mu.Lock()
+	*o.commands = append(*o.commands, cmd)
+	o.mu.Unlock()
+
+	n := atomic.AddInt32(o.running, 1)
+
+	for {
+		peak := atomic.LoadInt32(o.peak)
+
+		if n <= peak || atomic.CompareAndSwapInt32(o.peak, peak, n) {
+			break
+		}
+	}
+
+	ch := make(chan error)
+
+	go func() {
+		<-o.release
+		atomic.AddInt32(o.running, -1)
+
+		if strings.Contains(cmd, processErrFlag) {
+			ch <- errors.New(processErrFlag)
+			return
+		}
+
+		ch <- nil
+	}()
+
+	return ch, nil
+}
+
+type blockingClient struct {
+	client   *client.ComposeClient
+	release  chan struct{}
+	peak     int32
+	mu       sync.Mutex
+	commands []string
+}
+
+func newBlockingClient() *blockingClient {
+	b := &blockingClient{
+		release: make(chan struct{}),
+	}
+
+	var running int32
+
+	b.client = &client.ComposeClient{
+		NewCmd: func() client.Cmd {
+			return &blockingCmd{
+				running:  &running,
+				peak:     &b.peak,
+				release:  b.release,
+				mu:       &b.mu,
+				commands: &b.commands,
+			}
+		},
+	}
+
+	return b
+}
+
+func (b *blockingClient) Commands() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return append([]string(nil), b.commands...)
+}
+
+func TestManagerAdd(t *testing.T) {
+	m := client.NewManager(newBlockingClient().client, nil)
+
+	if _, err := m.Add("feature-a", nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := m.Add("feature-a", nil, nil); err == nil {
+		t.Error("expected an error adding a duplicate project")
+	}
+
+	if m.Get("feature-a") == nil {
+		t.Error("expected feature-a to be managed")
+	}
+
+	m.Remove("feature-a")
+
+	if m.Get("feature-a") != nil {
+		t.Error("expected feature-a to be removed")
+	}
+}
+
+func TestManagerUnknownProject(t *testing.T) {
+	m := client.NewManager(newBlockingClient().client, nil)
+
+	if _, err := m.Up("missing", nil, nil); err == nil {
+		t.Error("expected an error for an unmanaged project")
+	}
+}
+
+func TestManagerProjectOptions(t *testing.T) {
+	b := newBlockingClient()
+	m := client.NewManager(b.client, nil)
+
+	m.Add("feature-a", &client.GlobalOptions{
+		Files: []string{"docker-compose.ci.yml"},
+	}, nil)
+
+	ch, err := m.Up("feature-a", &client.UpOptions{Detach: true}, nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	close(b.release)
+
+	if err := <-ch; err != nil {
+		t.Error(err)
+	}
+
+	expected := "docker compose --file docker-compose.ci.yml --project-name feature-a up --detach"
+
+	if commands := b.Commands(); len(commands) != 1 || commands[0] != expected {
+		t.Errorf("expected %s, got %v", expected, commands)
+	}
+}
+
+func TestManagerBuildConcurrencyLimit(t *testing.T) {
+	b := newBlockingClient()
+	m := client.NewManager(b.client, &client.ManagerOptions{
+		MaxConcurrentBuilds: 2,
+	})
+
+	var chs []<-chan error
+
+	for _, name := range []string{"a", "b", "c", "d"} {
+		m.Add(name, nil, nil)
+
+		ch, err := m.Build(name, nil, nil)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		chs = append(chs, ch)
+	}
+
+	// Give every build the chance to start
+	time.Sleep(50 * time.Millisecond)
+
+	if n := len(b.Commands()); n != 2 {
+		t.Errorf("expected 2 builds to be running, got %d", n)
+	}
+
+	close(b.release)
+
+	for _, ch := range chs {
+		<-ch
+	}
+
+	if b.peak != 2 {
+		t.Errorf("expected at most 2 concurrent builds, got %d", b.peak)
+	}
+}
+
+func TestManagerUpPullBuildConcurrencyLimit(t *testing.T) {
+	b := newBlockingClient()
+	m := client.NewManager(b.client, &client.ManagerOptions{
+		MaxConcurrentBuilds: 1,
+	})
+
+	var chs []<-chan error
+
+	for _, name := range []string{"a", "b"} {
+		m.Add(name, nil, nil)
+
+		ch, err := m.Up(name, &client.UpOptions{Pull: client.PullPolicyFlagBuild}, nil)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		chs = append(chs, ch)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+
+	if n := len(b.Commands()); n != 1 {
+		t.Errorf("expected up with --pull build to count as a build, got %d running", n)
+	}
+
+	close(b.release)
+
+	for _, ch := range chs {
+		<-ch
+	}
+}
+
+func TestManagerSerialisesProjectOperations(t *testing.T) {
+	b := newBlockingClient()
+	m := client.NewManager(b.client, nil)
+
+	m.Add("feature-a", nil, nil)
+
+	upCh, _ := m.Up("feature-a", nil, nil)
+	downCh, _ := m.Down("feature-a", nil, nil)
+
+	time.Sleep(50 * time.Millisecond)
+
+	if n := len(b.Commands()); n != 1 {
+		t.Errorf("expected 1 command to be running, got %d", n)
+	}
+
+	close(b.release)
+
+	<-upCh
+	<-downCh
+
+	if b.peak != 1 {
+		t.Errorf("expected operations on one project not to overlap, got %d", b.peak)
+	}
+
+	expected := []string{
+		"docker compose --project-name feature-a up",
+		"docker compose --project-name feature-a down",
+	}
+
+	if commands := b.Commands(); strings.Join(commands, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected %v, got %v", expected, commands)
+	}
+}
+
+func TestManagerRunsProjectOperationsInOrder(t *testing.T) {
+	b := newBlockingClient()
+	m := client.NewManager(b.client, nil)
+
+	m.Add("feature-a", nil, nil)
+	close(b.release)
+
+	var (
+		chs      []<-chan error
+		expected []string
+	)
+
+	for i := 0; i < 20; i++ {
+		services := []string{fmt.Sprintf("service-%d", i)}
+
+		ch, err := m.Pull("feature-a", services, nil)
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		chs = append(chs, ch)
+		expected = append(expected, "docker compose --project-name feature-a pull "+services[0])
+	}
+
+	for _, ch := range chs {
+		<-ch
+	}
+
+	if commands := b.Commands(); strings.Join(commands, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("expected operations in the order they were started, got %v", commands)
+	}
+}
+
+func TestManagerList(t *testing.T) {
+	m := client.NewManager(newBlockingClient().client, nil)
+
+	m.Add("b", nil, map[string]string{"branch": "main"})
+	m.Add("a", nil, map[string]string{"branch": "feature"})
+	m.Add("c", nil, map[string]string{"branch": "feature"})
+
+	all := m.List(nil)
+
+	if len(all) != 3 || all[0].Name != "a" || all[2].Name != "c" {
+		t.Errorf("expected projects sorted by name, got %v", all)
+	}
+
+	features := m.List(&client.ProjectFilter{
+		Labels: map[string]string{"branch": "feature"},
+	})
+
+	if len(features) != 2 {
+		t.Errorf("expected 2 feature projects, got %d", len(features))
+	}
+
+	stale := m.List(&client.ProjectFilter{
+		UnusedFor: time.Hour,
+	})
+
+	if len(stale) != 0 {
+		t.Errorf("expected no stale projects, got %d", len(stale))
+	}
+}
+
+func TestManagerDownAll(t *testing.T) {
+	b := newBlockingClient()
+	m := client.NewManager(b.client, nil)
+
+	m.Add("a", nil, map[string]string{"branch": "feature"})
+	m.Add(processErrFlag, nil, map[string]string{"branch": "feature"})
+	m.Add("c", nil, map[string]string{"branch": "main"})
+
+	close(b.release)
+
+	err := <-m.DownAll(&client.ProjectFilter{
+		Labels: map[string]string{"branch": "feature"},
+	}, nil, nil)
+
+	var managerErr *client.ManagerError
+
+	if !errors.As(err, &managerErr) || len(managerErr.Errors) != 1 || managerErr.Errors[processErrFlag] == nil {
+		t.Errorf("expected a ManagerError for %s, got %v", processErrFlag, err)
+	}
+
+	if m.Get("a") != nil {
+		t.Error("expected a to be torn down and removed")
+	}
+
+	if m.Get(processErrFlag) == nil || m.Get("c") == nil {
+		t.Error("expected failed and unmatched projects to remain managed")
+	}
+}
+
+func TestManagerDiscover(t *testing.T) {
+	c := newQueryClient(map[string]string{
+		"docker compose ls --all --format json": `[{"Name":"ci-123","Status":"exited(2)","ConfigFiles":"/src/compose.yaml"},{"Name":"tracked","Status":"running(1)"}]`,
+		"docker compose --project-name ci-123 ps --all --format json": `{"Name":"ci-123-db-1","Service":"db","CreatedAt":"2024-01-10 12:00:00 +0000 UTC","Labels":"ci.branch=feature,ci.service=db,com.docker.compose.project=ci-123"}
+{"Name":"ci-123-web-1","Service":"web","CreatedAt":"2024-01-10 12:05:00 +0000 UTC","Labels":"ci.branch=feature,ci.service=web,com.docker.compose.project=ci-123"}
+`,
+	})
+
+	m := client.NewManager(c, nil)
+	m.Add("tracked", nil, map[string]string{"ci.branch": "feature"})
+
+	added, err := m.Discover()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(added) != 1 || added[0].Name != "ci-123" {
+		t.Fatalf("expected ci-123 to be discovered, got %v", added)
+	}
+
+	project := added[0]
+
+	if len(project.Labels) != 1 || project.Labels["ci.branch"] != "feature" {
+		t.Errorf("expected the labels every container has, got %v", project.Labels)
+	}
+
+	if lastUsed := project.LastUsed(); !lastUsed.Equal(time.Date(2024, 1, 10, 12, 5, 0, 0, time.UTC)) {
+		t.Errorf("expected the newest container's creation time, got %s", lastUsed)
+	}
+
+	stale := m.List(&client.ProjectFilter{
+		Labels:    map[string]string{"ci.branch": "feature"},
+		UnusedFor: time.Hour,
+	})
+
+	if len(stale) != 1 || stale[0] != project {
+		t.Errorf("expected only ci-123 to be stale, got %v", stale)
+	}
+}
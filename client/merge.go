@@ -0,0 +1,260 @@
+package client
+
+type ListMergeMode string
+
+const (
+	// Append the override's values to the list. This is the default.
+	ListMergeAppend ListMergeMode = ""
+
+	// Replace the list with the override's values. Replacing with an empty list clears it.
+	ListMergeReplace ListMergeMode = "replace"
+
+	// Remove the override's values from the list.
+	ListMergeRemove ListMergeMode = "remove"
+)
+
+// GlobalOptionField names a single-valued GlobalOptions field, for use with GlobalOptions.Clear
+type GlobalOptionField string
+
+const (
+	FieldInlineFileMode    GlobalOptionField = "InlineFileMode"
+	FieldProjectName       GlobalOptionField = "ProjectName"
+	FieldParallel          GlobalOptionField = "Parallel"
+	FieldANSI              GlobalOptionField = "ANSI"
+	FieldProgress          GlobalOptionField = "Progress"
+	FieldDryRun            GlobalOptionField = "DryRun"
+	FieldVerbose           GlobalOptionField = "Verbose"
+	FieldNoANSI            GlobalOptionField = "NoANSI"
+	FieldContext           GlobalOptionField = "Context"
+	FieldHost              GlobalOptionField = "Host"
+	FieldTLS               GlobalOptionField = "TLS"
+	FieldTLSCACert         GlobalOptionField = "TLSCACert"
+	FieldTLSCert           GlobalOptionField = "TLSCert"
+	FieldTLSKey            GlobalOptionField = "TLSKey"
+	FieldTLSVerify         GlobalOptionField = "TLSVerify"
+	FieldSkipHostnameCheck GlobalOptionField = "SkipHostnameCheck"
+	FieldProjectDirectory  GlobalOptionField = "ProjectDirectory"
+	FieldCompatibility     GlobalOptionField = "Compatibility"
+)
+
+// Merge returns a new GlobalOptions with each of the overrides applied in order. Neither o nor the overrides are modified.
+//
+// For each override:
+//
+// - Fields listed in Clear are reset to their zero value.
+//
+// - Non-empty strings and non-nil pointers replace the current value. SkipHostnameCheck is set if true.
+//
+// - Files, InlineFiles, Profiles and EnvFiles are appended, replaced or removed according to FilesMerge, InlineFilesMerge, ProfilesMerge and EnvFilesMerge.
+//
+// The merge control fields (Clear and the list merge modes) are not carried over to the result.
+//
+// Merge can be called on a nil GlobalOptions.
+func (o *GlobalOptions) Merge(overrides ...*GlobalOptions) *GlobalOptions {
+	merged := &GlobalOptions{}
+
+	if o != nil {
+		*merged = *o
+	}
+
+	merged.Files = mergeList(nil, merged.Files, ListMergeAppend)
+	merged.InlineFiles = mergeInlineFiles(nil, merged.InlineFiles, ListMergeAppend)
+	merged.Profiles = mergeList(nil, merged.Profiles, ListMergeAppend)
+	merged.EnvFiles = mergeList(nil, merged.EnvFiles, ListMergeAppend)
+	merged.FilesMerge = ListMergeAppend
+	merged.InlineFilesMerge = ListMergeAppend
+	merged.ProfilesMerge = ListMergeAppend
+	merged.EnvFilesMerge = ListMergeAppend
+	merged.Clear = nil
+
+	for _, override := range overrides {
+		if override == nil {
+			continue
+		}
+
+		for _, field := range override.Clear {
+			merged.clear(field)
+		}
+
+		merged.Files = mergeList(merged.Files, override.Files, override.FilesMerge)
+		merged.InlineFiles = mergeInlineFiles(merged.InlineFiles, override.InlineFiles, override.InlineFilesMerge)
+		merged.Profiles = mergeList(merged.Profiles, override.Profiles, override.ProfilesMerge)
+		merged.EnvFiles = mergeList(merged.EnvFiles, override.EnvFiles, override.EnvFilesMerge)
+
+		if override.InlineFileMode != "" {
+			merged.InlineFileMode = override.InlineFileMode
+		}
+
+		if override.ProjectName != "" {
+			merged.ProjectName = override.ProjectName
+		}
+
+		if override.Parallel != nil {
+			merged.Parallel = override.Parallel
+		}
+
+		if override.ANSI != "" {
+			merged.ANSI = override.ANSI
+		}
+
+		if override.Progress != "" {
+			merged.Progress = override.Progress
+		}
+
+		if override.DryRun != nil {
+			merged.DryRun = override.DryRun
+		}
+
+		if override.Verbose != nil {
+			merged.Verbose = override.Verbose
+		}
+
+		if override.NoANSI != nil {
+			merged.NoANSI = override.NoANSI
+		}
+
+		if override.Context != "" {
+			merged.Context = override.Context
+		}
+
+		if override.Host != "" {
+			merged.Host = override.Host
+		}
+
+		if override.TLS != nil {
+			merged.TLS = override.TLS
+		}
+
+		if override.TLSCACert != "" {
+			merged.TLSCACert = override.TLSCACert
+		}
+
+		if override.TLSCert != "" {
+			merged.TLSCert = override.TLSCert
+		}
+
+		if override.TLSKey != "" {
+			merged.TLSKey = override.TLSKey
+		}
+
+		if override.TLSVerify != nil {
+			merged.TLSVerify = override.TLSVerify
+		}
+
+		if override.SkipHostnameCheck {
+			merged.SkipHostnameCheck = override.SkipHostnameCheck
+		}
+
+		if override.ProjectDirectory != "" {
+			merged.ProjectDirectory = override.ProjectDirectory
+		}
+
+		if override.Compatibility != nil {
+			merged.Compatibility = override.Compatibility
+		}
+	}
+
+	return merged
+}
+
+func (o *GlobalOptions) clear(field GlobalOptionField) {
+	switch field {
+	case FieldInlineFileMode:
+		o.InlineFileMode = ""
+	case FieldProjectName:
+		o.ProjectName = ""
+	case FieldParallel:
+		o.Parallel = nil
+	case FieldANSI:
+		o.ANSI = ""
+	case FieldProgress:
+		o.Progress = ""
+	case FieldDryRun:
+		o.DryRun = nil
+	case FieldVerbose:
+		o.Verbose = nil
+	case FieldNoANSI:
+		o.NoANSI = nil
+	case FieldContext:
+		o.Context = ""
+	case FieldHost:
+		o.Host = ""
+	case FieldTLS:
+		o.TLS = nil
+	case FieldTLSCACert:
+		o.TLSCACert = ""
+	case FieldTLSCert:
+		o.TLSCert = ""
+	case FieldTLSKey:
+		o.TLSKey = ""
+	case FieldTLSVerify:
+		o.TLSVerify = nil
+	case FieldSkipHostnameCheck:
+		o.SkipHostnameCheck = false
+	case FieldProjectDirectory:
+		o.ProjectDirectory = ""
+	case FieldCompatibility:
+		o.Compatibility = nil
+	}
+}
+
+// mergeList returns a new list with values merged into base according to mode
+func mergeList(base, values []string, mode ListMergeMode) []string {
+	switch mode {
+	case ListMergeReplace:
+		return append([]string(nil), values...)
+
+	case ListMergeRemove:
+		var result []string
+
+		for _, value := range base {
+			if !containsString(values, value) {
+				result = append(result, value)
+			}
+		}
+
+		return result
+
+	default:
+		if len(base) == 0 && len(values) == 0 {
+			return nil
+		}
+
+		return append(append([]string(nil), base...), values...)
+	}
+}
+
+// mergeInlineFiles returns a new list with files merged into base according to mode. Files are removed by Name.
+func mergeInlineFiles(base, files []ComposeFile, mode ListMergeMode) []ComposeFile {
+	switch mode {
+	case ListMergeReplace:
+		return append([]ComposeFile(nil), files...)
+
+	case ListMergeRemove:
+		var result []ComposeFile
+
+		for _, file := range base {
+			removed := false
+
+			for _, f := range files {
+				if f.Name == file.Name {
+					removed = true
+					break
+				}
+			}
+
+			if !removed {
+				result = append(result, file)
+			}
+		}
+
+		return result
+
+	default:
+		if len(base) == 0 && len(files) == 0 {
+			return nil
+		}
+
+		return append(append([]ComposeFile(nil), base...), files...)
+	}
+}
@@ -0,0 +1,197 @@
+package client_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/harrim91/docker-compose-go/client"
+)
+
+func TestMergeDefaults(t *testing.T) {
+	verbose := true
+
+	base := &client.GlobalOptions{
+		Files:       []string{"file1"},
+		Profiles:    []string{"profile1"},
+		ProjectName: "my-project",
+		Verbose:     &verbose,
+	}
+
+	merged := base.Merge(&client.GlobalOptions{
+		Files:       []string{"file2"},
+		ProjectName: "override-project",
+	})
+
+	if !reflect.DeepEqual(merged.Files, []string{"file1", "file2"}) {
+		t.Errorf("expected files to be appended, got %v", merged.Files)
+	}
+
+	if !reflect.DeepEqual(merged.Profiles, []string{"profile1"}) {
+		t.Errorf("expected profiles to be kept, got %v", merged.Profiles)
+	}
+
+	if merged.ProjectName != "override-project" || merged.Verbose != &verbose {
+		t.Errorf("unexpected merge result: %+v", merged)
+	}
+
+	if !reflect.DeepEqual(base.Files, []string{"file1"}) || base.ProjectName != "my-project" {
+		t.Errorf("expected base to be unmodified, got %+v", base)
+	}
+}
+
+func TestMergeNil(t *testing.T) {
+	var base *client.GlobalOptions
+
+	merged := base.Merge(nil, &client.GlobalOptions{
+		ProjectName: "my-project",
+	})
+
+	if merged.ProjectName != "my-project" {
+		t.Errorf("expected my-project, got %s", merged.ProjectName)
+	}
+}
+
+func TestMergeReplaceList(t *testing.T) {
+	base := &client.GlobalOptions{
+		Files: []string{"file1", "file2"},
+	}
+
+	merged := base.Merge(&client.GlobalOptions{
+		Files:      []string{"file3"},
+		FilesMerge: client.ListMergeReplace,
+	})
+
+	if !reflect.DeepEqual(merged.Files, []string{"file3"}) {
+		t.Errorf("expected files to be replaced, got %v", merged.Files)
+	}
+
+	if merged.FilesMerge != client.ListMergeAppend {
+		t.Errorf("expected merge mode not to be carried over, got %s", merged.FilesMerge)
+	}
+}
+
+func TestMergeClearList(t *testing.T) {
+	base := &client.GlobalOptions{
+		EnvFiles: []string{".env"},
+	}
+
+	merged := base.Merge(&client.GlobalOptions{
+		EnvFilesMerge: client.ListMergeReplace,
+	})
+
+	if len(merged.EnvFiles) != 0 {
+		t.Errorf("expected env files to be cleared, got %v", merged.EnvFiles)
+	}
+}
+
+func TestMergeRemoveFromList(t *testing.T) {
+	base := &client.GlobalOptions{
+		Profiles: []string{"profile1", "profile2", "profile3"},
+	}
+
+	merged := base.Merge(&client.GlobalOptions{
+		Profiles:      []string{"profile2"},
+		ProfilesMerge: client.ListMergeRemove,
+	})
+
+	if !reflect.DeepEqual(merged.Profiles, []string{"profile1", "profile3"}) {
+		t.Errorf("expected profile2 to be removed, got %v", merged.Profiles)
+	}
+}
+
+func TestMergeClearFields(t *testing.T) {
+	tlsVerify := true
+
+	base := &client.GlobalOptions{
+		ProjectName: "my-project",
+		Host:        "tcp://127.0.0.1:2376",
+		TLSVerify:   &tlsVerify,
+	}
+
+	merged := base.Merge(&client.GlobalOptions{
+		Clear: []client.GlobalOptionField{
+			client.FieldProjectName,
+			client.FieldTLSVerify,
+		},
+	})
+
+	if merged.ProjectName != "" || merged.TLSVerify != nil {
+		t.Errorf("expected fields to be cleared, got %+v", merged)
+	}
+
+	if merged.Host != "tcp://127.0.0.1:2376" {
+		t.Errorf("expected host to be kept, got %s", merged.Host)
+	}
+
+	if merged.Clear != nil {
+		t.Errorf("expected clear not to be carried over, got %v", merged.Clear)
+	}
+}
+
+func TestMergeClearThenSet(t *testing.T) {
+	base := &client.GlobalOptions{
+		ProjectName: "my-project",
+	}
+
+	merged := base.Merge(
+		&client.GlobalOptions{
+			Clear: []client.GlobalOptionField{client.FieldProjectName},
+		},
+		&client.GlobalOptions{
+			ProjectName: "other-project",
+		},
+	)
+
+	if merged.ProjectName != "other-project" {
+		t.Errorf("expected later overrides to apply, got %s", merged.ProjectName)
+	}
+}
+
+func TestRunCommandMergeOverrides(t *testing.T) {
+	cmd := &MockCmd{}
+
+	c := &client.ComposeClient{
+		GlobalOptions: &client.GlobalOptions{
+			Files:       []string{"file1", "file2"},
+			Profiles:    []string{"profile1", "profile2"},
+			ProjectName: "my-project",
+		},
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	cmd.On("Run", "docker compose --file file3 --profile profile1 foo bar")
+
+	c.RunCommand("foo", "bar", nil, nil, &client.GlobalOptions{
+		Files:         []string{"file3"},
+		FilesMerge:    client.ListMergeReplace,
+		Profiles:      []string{"profile2"},
+		ProfilesMerge: client.ListMergeRemove,
+		Clear:         []client.GlobalOptionField{client.FieldProjectName},
+	})
+
+	cmd.AssertExpectations(t)
+}
+
+func TestMergeRemoveInlineFile(t *testing.T) {
+	base := &client.GlobalOptions{
+		InlineFiles: []client.ComposeFile{
+			{Name: "base.yml", Content: []byte("services: {}")},
+			{Name: "override.yml", Content: []byte("services: {}")},
+		},
+	}
+
+	merged := base.Merge(&client.GlobalOptions{
+		InlineFiles:      []client.ComposeFile{{Name: "override.yml"}},
+		InlineFilesMerge: client.ListMergeRemove,
+	})
+
+	if len(merged.InlineFiles) != 1 || merged.InlineFiles[0].Name != "base.yml" {
+		t.Errorf("expected only base.yml, got %+v", merged.InlineFiles)
+	}
+
+	if len(base.InlineFiles) != 2 {
+		t.Errorf("expected base to be unmodified, got %+v", base.InlineFiles)
+	}
+}
@@ -0,0 +1,310 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// The label compose sets on each container, with the hash of the service config the container was created from
+const configHashLabel = "com.docker.compose.config-hash"
+
+// ErrNoContainerLabels is returned by Plan when `docker compose ps` doesn't output the containers' labels, e.g. in
+// older versions of compose, so the config hashes they were created from aren't known
+var ErrNoContainerLabels = errors.New("docker compose ps did not output the containers' labels")
+
+// PlanAction is what `up` would do to a service's containers
+type PlanAction string
+
+const (
+	// The service has no containers, so they would be created
+	PlanCreate PlanAction = "create"
+
+	// The service config has changed since a container was created, so it would be recreated
+	PlanRecreate PlanAction = "recreate"
+
+	// The service's containers match its config, so would be left alone
+	PlanUnchanged PlanAction = "unchanged"
+
+	// The containers belong to a service that is no longer in the config. `up` leaves them running unless RemoveOrphans is set.
+	PlanOrphaned PlanAction = "orphaned"
+)
+
+var planSymbols = map[PlanAction]string{
+	PlanCreate:    "+",
+	PlanRecreate:  "~",
+	PlanUnchanged: " ",
+	PlanOrphaned:  "-",
+}
+
+type PlanOptions struct {
+	// Only plan these services. Orphaned containers are only reported when no services are given.
+	Services []string
+}
+
+// PlanContainer is an existing container of a service
+type PlanContainer struct {
+	Name string
+
+	// The hash of the service config the container was created from
+	ConfigHash string
+}
+
+// ServicePlan is what `up` would do to a service
+type ServicePlan struct {
+	Service string
+	Action  PlanAction
+
+	// The hash of the current service config. Empty for orphaned services.
+	ConfigHash string
+
+	// The service's existing containers
+	Containers []PlanContainer
+}
+
+// Plan is what `up` would do to each service, sorted by service
+type Plan struct {
+	Services []ServicePlan
+}
+
+// Changed reports whether `up` would create or recreate any containers
+func (p *Plan) Changed() bool {
+	for _, service := range p.Services {
+		if service.Action == PlanCreate || service.Action == PlanRecreate {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Render writes the plan in a human-readable form, e.g.
+//
+//	+ worker (create)
+//	~ web (recreate)
+//	  db (unchanged)
+//	- legacy (orphaned)
+//
+//	Plan: 1 to create, 1 to recreate, 1 unchanged, 1 orphaned.
+func (p *Plan) Render(w io.Writer) error {
+	counts := map[PlanAction]int{}
+
+	for _, service := range p.Services {
+		counts[service.Action]++
+
+		if _, err := fmt.Fprintf(w, "%s %s (%s)\n", planSymbols[service.Action], service.Service, service.Action); err != nil {
+			return err
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "\nPlan: %d to create, %d to recreate, %d unchanged, %d orphaned.\n",
+		counts[PlanCreate], counts[PlanRecreate], counts[PlanUnchanged], counts[PlanOrphaned])
+
+	return err
+}
+
+// String returns the rendered plan
+func (p *Plan) String() string {
+	var b bytes.Buffer
+
+	p.Render(&b)
+
+	return b.String()
+}
+
+// Plan predicts which services `docker compose up` would create or recreate.
+//
+// Compose recreates a container when the hash of its service config changes. The plan compares the hashes from
+// `docker compose config --hash "*"` to the `com.docker.compose.config-hash` labels of the project's containers,
+// from `docker compose ps --all`. If the containers' labels aren't in the ps output, ErrNoContainerLabels is returned.
+//
+// The plan doesn't account for containers recreated because their image changed (e.g. when `up` pulls or builds
+// a new image), or for UpOptions that change what `up` does, like ForceRecreate, NoRecreate and AlwaysRecreateDeps.
+func (c *ComposeClient) Plan(opts *PlanOptions, overrides ...*GlobalOptions) (*Plan, error) {
+	if opts == nil {
+		opts = &PlanOptions{}
+	}
+
+	// The config and the containers are queried separately, so an inline file's Reader must be read once up front
+	if err := c.bufferInlineFiles(overrides...); err != nil {
+		return nil, err
+	}
+
+	hashes, err := c.ConfigHashes(nil, overrides...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	ps, err := c.RunQuery("ps", "--all --format json", overrides...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	containers, err := parsePsContainers(ps)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return newPlan(hashes, containers, opts.Services), nil
+}
+
+// newPlan compares the config hash of each service to the hashes of its containers
+func newPlan(hashes map[string]string, containers map[string][]PlanContainer, services []string) *Plan {
+	plan := &Plan{}
+
+	services = append([]string(nil), services...)
+
+	if len(services) == 0 {
+		for service := range hashes {
+			services = append(services, service)
+		}
+
+		for service := range containers {
+			if _, ok := hashes[service]; !ok {
+				services = append(services, service)
+			}
+		}
+	}
+
+	sort.Strings(services)
+
+	for _, service := range services {
+		hash, inConfig := hashes[service]
+
+		s := ServicePlan{
+			Service:    service,
+			Action:     PlanUnchanged,
+			ConfigHash: hash,
+			Containers: containers[service],
+		}
+
+		switch {
+		case !inConfig:
+			s.Action = PlanOrphaned
+		case len(s.Containers) == 0:
+			s.Action = PlanCreate
+		default:
+			for _, container := range s.Containers {
+				if container.ConfigHash != hash {
+					s.Action = PlanRecreate
+				}
+			}
+		}
+
+		plan.Services = append(plan.Services, s)
+	}
+
+	return plan
+}
+
+// psContainer is a container in the output of `docker compose ps --format json`
+type psContainer struct {
+	Name    string
+	Service string
+	Image   string
+
+	// e.g. `2024-01-10 12:00:00 +0000 UTC`
+	CreatedAt string
+
+	// Older versions of compose output the labels as a map, and newer versions as a comma separated string of key=value pairs
+	Labels json.RawMessage
+}
+
+// The format of psContainer.CreatedAt
+const psCreatedAtLayout = "2006-01-02 15:04:05 -0700 MST"
+
+func (p *psContainer) labels() map[string]string {
+	labels := map[string]string{}
+
+	if err := json.Unmarshal(p.Labels, &labels); err == nil {
+		return labels
+	}
+
+	var s string
+
+	json.Unmarshal(p.Labels, &s)
+
+	for _, label := range strings.Split(s, ",") {
+		if key, value, ok := strings.Cut(label, "="); ok {
+			labels[key] = value
+		}
+	}
+
+	return labels
+}
+
+func (p *psContainer) configHash() string {
+	return p.labels()[configHashLabel]
+}
+
+// created returns when the container was created, or the zero time if it isn't known
+func (p *psContainer) created() time.Time {
+	created, _ := time.Parse(psCreatedAtLayout, p.CreatedAt)
+
+	return created
+}
+
+// decodePs decodes the output of `docker compose ps --format json`.
+//
+// Older versions of compose output a JSON array, and newer versions a JSON object per line.
+func decodePs(out []byte) ([]psContainer, error) {
+	var ps []psContainer
+
+	if trimmed := bytes.TrimSpace(out); bytes.HasPrefix(trimmed, []byte("[")) {
+		if err := json.Unmarshal(trimmed, &ps); err != nil {
+			return nil, err
+		}
+	} else {
+		decoder := json.NewDecoder(bytes.NewReader(trimmed))
+
+		for decoder.More() {
+			var container psContainer
+
+			if err := decoder.Decode(&container); err != nil {
+				return nil, err
+			}
+
+			ps = append(ps, container)
+		}
+	}
+
+	return ps, nil
+}
+
+// parsePsContainers parses the output of `docker compose ps --format json` into the containers of each service
+func parsePsContainers(out []byte) (map[string][]PlanContainer, error) {
+	ps, err := decodePs(out)
+
+	if err != nil {
+		return nil, err
+	}
+
+	containers := map[string][]PlanContainer{}
+	labelled := false
+
+	for _, container := range ps {
+		if len(container.labels()) > 0 {
+			labelled = true
+		}
+
+		containers[container.Service] = append(containers[container.Service], PlanContainer{
+			Name:       container.Name,
+			ConfigHash: container.configHash(),
+		})
+	}
+
+	// Without labels every container would look out of date
+	if len(ps) > 0 && !labelled {
+		return nil, ErrNoContainerLabels
+	}
+
+	return containers, nil
+}
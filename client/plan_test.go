@@ -0,0 +1,179 @@
+package client_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/harrim91/docker-compose-go/client"
+)
+
+// queryCmd writes the output for the command it is run with, or fails if there isn't one
+type queryCmd struct {
+	outputs map[string]string
+	stdout  io.Writer
+}
+
+func (o *queryCmd) SetStdin(stdin io.Reader) {}
+
+func (o *queryCmd) SetStdout(stdout io.Writer) {
+	o.stdout = stdout
+}
+
+func (o *queryCmd) SetStderr(stderr io.Writer) {}
+
+func (o *queryCmd) Run(cmd string) (<-chan error, error) {
+	output, ok := o.outputs[cmd]
+
+	if !ok {
+		return nil, fmt.Errorf("unexpected command: %s", cmd)
+	}
+
+	ch := make(chan error)
+
+	go func() {
+		io.WriteString(o.stdout, output)
+		ch <- nil
+	}()
+
+	return ch, nil
+}
+
+// newQueryClient returns a client whose commands write the given outputs
+func newQueryClient(outputs map[string]string) *client.ComposeClient {
+	return &client.ComposeClient{
+		NewCmd: func() client.Cmd {
+			return &queryCmd{outputs: outputs}
+		},
+	}
+}
+
+const (
+	planHashes = "cache 111\ndb 222\nweb 333\nworker 444\n"
+
+	planPs = `{"Name":"app-db-1","Service":"db","Labels":"com.docker.compose.project=app,com.docker.compose.config-hash=222"}
+{"Name":"app-web-1","Service":"web","Labels":"com.docker.compose.config-hash=333,com.docker.compose.project=app"}
+{"Name":"app-web-2","Service":"web","Labels":"com.docker.compose.config-hash=old"}
+{"Name":"app-legacy-1","Service":"legacy","Labels":"com.docker.compose.config-hash=555"}
+{"Name":"app-cache-1","Service":"cache","Labels":"com.docker.compose.config-hash=111"}
+`
+
+	// Older versions of compose output an array, with the labels as a map
+	planPsArray = `[{"Name":"app-db-1","Service":"db","Labels":{"com.docker.compose.config-hash":"222"}},
+{"Name":"app-web-1","Service":"web","Labels":{"com.docker.compose.config-hash":"333"}}]`
+)
+
+func TestPlan(t *testing.T) {
+	c := newQueryClient(map[string]string{
+		`docker compose config --format json --hash="*"`: planHashes,
+		"docker compose ps --all --format json":          planPs,
+	})
+
+	plan, err := c.Plan(nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []client.ServicePlan{
+		{Service: "cache", Action: client.PlanUnchanged, ConfigHash: "111", Containers: []client.PlanContainer{{Name: "app-cache-1", ConfigHash: "111"}}},
+		{Service: "db", Action: client.PlanUnchanged, ConfigHash: "222", Containers: []client.PlanContainer{{Name: "app-db-1", ConfigHash: "222"}}},
+		{Service: "legacy", Action: client.PlanOrphaned, Containers: []client.PlanContainer{{Name: "app-legacy-1", ConfigHash: "555"}}},
+		{Service: "web", Action: client.PlanRecreate, ConfigHash: "333", Containers: []client.PlanContainer{
+			{Name: "app-web-1", ConfigHash: "333"},
+			{Name: "app-web-2", ConfigHash: "old"},
+		}},
+		{Service: "worker", Action: client.PlanCreate, ConfigHash: "444"},
+	}
+
+	if !reflect.DeepEqual(plan.Services, expected) {
+		t.Errorf("expected %+v, got %+v", expected, plan.Services)
+	}
+
+	if !plan.Changed() {
+		t.Error("expected the plan to have changes")
+	}
+
+	rendered := `  cache (unchanged)
+  db (unchanged)
+- legacy (orphaned)
+~ web (recreate)
++ worker (create)
+
+Plan: 1 to create, 1 to recreate, 2 unchanged, 1 orphaned.
+`
+
+	if plan.String() != rendered {
+		t.Errorf("expected:\n%s\ngot:\n%s", rendered, plan.String())
+	}
+}
+
+func TestPlanServices(t *testing.T) {
+	c := newQueryClient(map[string]string{
+		`docker compose --project-name app config --format json --hash="*"`: planHashes,
+		"docker compose --project-name app ps --all --format json":          planPsArray,
+	})
+
+	plan, err := c.Plan(&client.PlanOptions{Services: []string{"web", "db"}}, &client.GlobalOptions{ProjectName: "app"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []client.ServicePlan{
+		{Service: "db", Action: client.PlanUnchanged, ConfigHash: "222", Containers: []client.PlanContainer{{Name: "app-db-1", ConfigHash: "222"}}},
+		{Service: "web", Action: client.PlanUnchanged, ConfigHash: "333", Containers: []client.PlanContainer{{Name: "app-web-1", ConfigHash: "333"}}},
+	}
+
+	if !reflect.DeepEqual(plan.Services, expected) {
+		t.Errorf("expected %+v, got %+v", expected, plan.Services)
+	}
+
+	if plan.Changed() {
+		t.Error("expected the plan to have no changes")
+	}
+}
+
+func TestPlanInlineFileReader(t *testing.T) {
+	var inputs []string
+
+	content := "services: {web: {image: nginx}}"
+
+	c := &client.ComposeClient{
+		NewCmd: func() client.Cmd {
+			return &inlineQueryCmd{
+				queryCmd: queryCmd{outputs: map[string]string{
+					`docker compose --file - config --format json --hash="*"`: planHashes,
+					"docker compose --file - ps --all --format json":          planPs,
+				}},
+				inputs: &inputs,
+			}
+		},
+	}
+
+	_, err := c.Plan(nil, &client.GlobalOptions{
+		InlineFiles: []client.ComposeFile{{Reader: strings.NewReader(content)}},
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(inputs) != 2 || inputs[0] != content || inputs[1] != content {
+		t.Errorf("expected both commands to read the inline file, got %q", inputs)
+	}
+}
+
+func TestPlanNoLabels(t *testing.T) {
+	c := newQueryClient(map[string]string{
+		`docker compose config --format json --hash="*"`: planHashes,
+		"docker compose ps --all --format json":          `{"Name":"app-db-1","Service":"db"}` + "\n",
+	})
+
+	if _, err := c.Plan(nil); !errors.Is(err, client.ErrNoContainerLabels) {
+		t.Errorf("expected ErrNoContainerLabels, got %v", err)
+	}
+}
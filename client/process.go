@@ -0,0 +1,184 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"time"
+)
+
+// ErrSignalUnsupported is returned when signalling a Process whose Cmd doesn't implement Signaler
+var ErrSignalUnsupported = errors.New("command does not support signals")
+
+// ProcessStater is implemented by Cmds that expose the underlying OS process
+type ProcessStater interface {
+	// Pid returns the process ID, or 0 if the command hasn't started
+	Pid() int
+
+	// ProcessState returns the state of the process once it has exited, or nil if it is still running
+	ProcessState() *os.ProcessState
+}
+
+// ProcessUsage is the resource usage of an exited Process
+type ProcessUsage struct {
+	// CPU time spent in user mode
+	UserTime time.Duration
+
+	// CPU time spent in kernel mode
+	SystemTime time.Duration
+
+	// Peak resident set size in bytes. Only reported on Linux.
+	MaxRSS int64
+}
+
+// Process is a handle on a running docker compose command.
+//
+// It should be created with `RunProcess` or one of the specific command methods (e.g. UpProcess, DownProcess).
+type Process struct {
+	cmd     Cmd
+	started time.Time
+	done    chan struct{}
+	mu      sync.Mutex
+	ended   time.Time
+	err     error
+}
+
+// newProcess returns a Process that completes when ch emits
+func newProcess(cmd Cmd, started time.Time, ch <-chan error) *Process {
+	p := &Process{
+		cmd:     cmd,
+		started: started,
+		done:    make(chan struct{}),
+	}
+
+	go func() {
+		err := <-ch
+
+		p.mu.Lock()
+		p.ended = time.Now()
+		p.err = err
+		p.mu.Unlock()
+
+		close(p.done)
+	}()
+
+	return p
+}
+
+// processErrors adapts the result of a Process method to the channel API used by RunCommand
+func processErrors(p *Process, err error) (<-chan error, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	return p.Errors(), nil
+}
+
+// Pid returns the process ID of the command, or 0 if the Cmd doesn't implement ProcessStater
+func (p *Process) Pid() int {
+	if stater, ok := p.cmd.(ProcessStater); ok {
+		return stater.Pid()
+	}
+
+	return 0
+}
+
+// Signal sends a signal to the command. Returns ErrSignalUnsupported if the Cmd doesn't implement Signaler.
+func (p *Process) Signal(sig os.Signal) error {
+	signaler, ok := p.cmd.(Signaler)
+
+	if !ok {
+		return ErrSignalUnsupported
+	}
+
+	return signaler.Signal(sig)
+}
+
+// Done returns a channel that is closed once the command has completed
+func (p *Process) Done() <-chan struct{} {
+	return p.done
+}
+
+// Running reports whether the command is still running
+func (p *Process) Running() bool {
+	select {
+	case <-p.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// Wait waits for the command to complete and returns its error.
+//
+// If ctx is done first, ctx.Err() is returned and the command is left running.
+func (p *Process) Wait(ctx context.Context) error {
+	select {
+	case <-p.done:
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		return p.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Errors returns a channel that will emit a single error and then close once the command has completed.
+//
+// This is the channel returned by RunCommand and the specific command methods.
+func (p *Process) Errors() <-chan error {
+	ch := make(chan error)
+
+	go func() {
+		defer close(ch)
+
+		ch <- p.Wait(context.Background())
+	}()
+
+	return ch
+}
+
+// Started returns when the command was started
+func (p *Process) Started() time.Time {
+	return p.started
+}
+
+// Ended returns when the command completed, or the zero time if it is still running
+func (p *Process) Ended() time.Time {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.ended
+}
+
+// State returns the state of the exited process.
+//
+// Returns nil if the command is still running, or the Cmd doesn't implement ProcessStater.
+func (p *Process) State() *os.ProcessState {
+	if p.Running() {
+		return nil
+	}
+
+	if stater, ok := p.cmd.(ProcessStater); ok {
+		return stater.ProcessState()
+	}
+
+	return nil
+}
+
+// Usage returns the resource usage of the exited process, or nil if its State is not available
+func (p *Process) Usage() *ProcessUsage {
+	state := p.State()
+
+	if state == nil {
+		return nil
+	}
+
+	return &ProcessUsage{
+		UserTime:   state.UserTime(),
+		SystemTime: state.SystemTime(),
+		MaxRSS:     maxRSS(state),
+	}
+}
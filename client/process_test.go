@@ -0,0 +1,209 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/harrim91/docker-compose-go/client"
+	"github.com/stretchr/testify/mock"
+)
+
+const processPid = 42
+
+// processCmd runs until it is signalled, then reports the state of a real process that has exited
+type processCmd struct {
+	signal chan os.Signal
+	mu     sync.Mutex
+	state  *os.ProcessState
+}
+
+func (o *processCmd) SetStdin(stdin io.Reader) {}
+
+func (o *processCmd) SetStdout(stdout io.Writer) {}
+
+func (o *processCmd) SetStderr(stderr io.Writer) {}
+
+func (o *processCmd) Signal(sig os.Signal) error {
+	o.signal <- sig
+	return nil
+}
+
+func (o *processCmd) Pid() int {
+	return processPid
+}
+
+func (o *processCmd) ProcessState() *os.ProcessState {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return o.state
+}
+
+func (o *processCmd) Run(cmd string) (<-chan error, error) {
+	ch := make(chan error)
+
+	go func() {
+		sig := <-o.signal
+
+		execcmd := exec.Command(os.Args[0], "-test.run=^$")
+		execcmd.Run()
+
+		o.mu.Lock()
+		o.state = execcmd.ProcessState
+		o.mu.Unlock()
+
+		ch <- errors.New("signal: " + sig.String())
+	}()
+
+	return ch, nil
+}
+
+func TestRunProcess(t *testing.T) {
+	cmd := &processCmd{signal: make(chan os.Signal, 1)}
+
+	c := &client.ComposeClient{
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	before := time.Now()
+
+	p, err := c.RunProcess("up", "", nil, nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !p.Running() || p.State() != nil || p.Usage() != nil || !p.Ended().IsZero() {
+		t.Error("expected the process to be running")
+	}
+
+	if p.Pid() != processPid {
+		t.Errorf("expected pid %d, got %d", processPid, p.Pid())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	if err := p.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected %v, got %v", context.DeadlineExceeded, err)
+	}
+
+	if err := p.Signal(syscall.SIGTERM); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case <-p.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the process to complete after being signalled")
+	}
+
+	expected := "signal: terminated"
+
+	if err := p.Wait(context.Background()); err == nil || err.Error() != expected {
+		t.Errorf("expected error '%s', got: '%v'", expected, err)
+	}
+
+	if p.Running() {
+		t.Error("expected the process to have completed")
+	}
+
+	if p.Started().Before(before) || p.Ended().Before(p.Started()) {
+		t.Errorf("unexpected timestamps: started %v, ended %v", p.Started(), p.Ended())
+	}
+
+	if state := p.State(); state == nil || !state.Exited() {
+		t.Errorf("expected an exited process state, got %v", state)
+	}
+
+	if p.Usage() == nil {
+		t.Error("expected resource usage")
+	}
+}
+
+func TestRunProcessErrors(t *testing.T) {
+	cmd := &MockCmd{}
+
+	c := &client.ComposeClient{
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	cmd.On("Run", "docker compose up "+processErrFlag)
+
+	p, err := c.RunProcess("up", processErrFlag, nil, nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = <-p.Errors()
+
+	if err == nil || err.Error() != processErrFlag {
+		t.Errorf("expected error %s, got %v", processErrFlag, err)
+	}
+
+	if _, ok := <-p.Errors(); !ok {
+		t.Error("expected every Errors channel to emit the result")
+	}
+
+	if err := p.Signal(syscall.SIGTERM); !errors.Is(err, client.ErrSignalUnsupported) {
+		t.Errorf("expected ErrSignalUnsupported, got %v", err)
+	}
+
+	if p.Pid() != 0 || p.State() != nil || p.Usage() != nil {
+		t.Error("expected no process details from a Cmd that doesn't implement ProcessStater")
+	}
+}
+
+func TestRunProcessRunError(t *testing.T) {
+	cmd := &MockCmd{}
+
+	c := &client.ComposeClient{
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	cmd.On("Run", mock.Anything)
+
+	p, err := c.RunProcess("up", runErrFlag, nil, nil)
+
+	if p != nil || err == nil || err.Error() != runErrFlag {
+		t.Errorf("expected error %s, got %v", runErrFlag, err)
+	}
+}
+
+func TestUpProcess(t *testing.T) {
+	cmd := &MockCmd{}
+
+	c := &client.ComposeClient{
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	cmd.On("Run", "docker compose up --detach")
+
+	p, err := c.UpProcess(&client.UpOptions{Detach: true}, nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := p.Wait(context.Background()); err != nil {
+		t.Error(err)
+	}
+
+	cmd.AssertExpectations(t)
+}
@@ -0,0 +1,18 @@
+//go:build linux
+
+package client
+
+import (
+	"os"
+	"syscall"
+)
+
+// maxRSS returns the peak resident set size of the process in bytes
+func maxRSS(state *os.ProcessState) int64 {
+	if rusage, ok := state.SysUsage().(*syscall.Rusage); ok {
+		// Linux reports ru_maxrss in kilobytes
+		return int64(rusage.Maxrss) * 1024
+	}
+
+	return 0
+}
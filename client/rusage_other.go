@@ -0,0 +1,10 @@
+//go:build !linux
+
+package client
+
+import "os"
+
+// maxRSS is not reported outside of Linux
+func maxRSS(state *os.ProcessState) int64 {
+	return 0
+}
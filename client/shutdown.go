@@ -0,0 +1,294 @@
+package client
+
+import (
+	"errors"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// ErrShutdownTimeout is returned when running commands or teardown don't complete within ShutdownOptions.Timeout
+var ErrShutdownTimeout = errors.New("timed out waiting for shutdown")
+
+// Signaler is implemented by Cmds that can forward a signal to the running docker compose process
+type Signaler interface {
+	Signal(sig os.Signal) error
+}
+
+// ShutdownOptions configures the shutdown hook
+type ShutdownOptions struct {
+	// Signals that trigger a shutdown (default: SIGINT, SIGTERM)
+	Signals []os.Signal
+
+	// Maximum time to wait for running commands to exit, and then again for tracked projects to be torn down (default: 30s)
+	Timeout time.Duration
+
+	// Options for the `docker compose down` run for each tracked project
+	Down *DownOptions
+
+	// stderr from `docker compose down` is written to this io.Writer
+	Writer io.Writer
+
+	// Called with exit code 128+signal once teardown has completed (default: os.Exit)
+	Exit func(code int)
+}
+
+type runningCmd struct {
+	cmd  Cmd
+	done chan struct{}
+}
+
+// ShutdownHook tears down projects started by a ComposeClient when the process receives a signal.
+// It should be created with `EnableShutdownHook`.
+type ShutdownHook struct {
+	client   *ComposeClient
+	opts     ShutdownOptions
+	mu       sync.Mutex
+	running  map[*runningCmd]struct{}
+	projects map[string][]*GlobalOptions
+	signals  chan os.Signal
+	stop     chan struct{}
+}
+
+// EnableShutdownHook starts tracking the commands run and the projects brought up by the client.
+//
+// When one of the configured signals is received, the signal is forwarded to every running docker compose process,
+// then `docker compose down` is run for every project brought up with Up or UpWithResult and not since brought down with Down.
+// Finally, the process exits.
+//
+// The hook should be enabled before the client runs any commands. Forwarding signals requires a Cmd that implements Signaler.
+func (c *ComposeClient) EnableShutdownHook(opts *ShutdownOptions) *ShutdownHook {
+	h := &ShutdownHook{
+		client:   c,
+		running:  map[*runningCmd]struct{}{},
+		projects: map[string][]*GlobalOptions{},
+		signals:  make(chan os.Signal, 1),
+		stop:     make(chan struct{}),
+	}
+
+	if opts != nil {
+		h.opts = *opts
+	}
+
+	if len(h.opts.Signals) == 0 {
+		h.opts.Signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+
+	if h.opts.Timeout == 0 {
+		h.opts.Timeout = 30 * time.Second
+	}
+
+	if h.opts.Exit == nil {
+		h.opts.Exit = os.Exit
+	}
+
+	c.shutdownMu.Lock()
+	c.shutdown = h
+	c.shutdownMu.Unlock()
+
+	signal.Notify(h.signals, h.opts.Signals...)
+
+	go func() {
+		select {
+		case sig := <-h.signals:
+			h.Shutdown(sig)
+
+			code := 1
+
+			if s, ok := sig.(syscall.Signal); ok {
+				code = 128 + int(s)
+			}
+
+			h.opts.Exit(code)
+		case <-h.stop:
+		}
+	}()
+
+	return h
+}
+
+// Stop disables the hook. Commands and projects are no longer tracked, and signals are no longer intercepted.
+func (h *ShutdownHook) Stop() {
+	signal.Stop(h.signals)
+	close(h.stop)
+
+	h.client.shutdownMu.Lock()
+	defer h.client.shutdownMu.Unlock()
+
+	// Another hook may have been enabled since
+	if h.client.shutdown == h {
+		h.client.shutdown = nil
+	}
+}
+
+// shutdownHook returns the enabled shutdown hook, or nil if there isn't one
+func (c *ComposeClient) shutdownHook() *ShutdownHook {
+	c.shutdownMu.Lock()
+	defer c.shutdownMu.Unlock()
+
+	return c.shutdown
+}
+
+// Projects returns the number of projects that would be torn down on shutdown.
+func (h *ShutdownHook) Projects() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	return len(h.projects)
+}
+
+// Shutdown forwards sig to every running docker compose process, waits for them to exit, then tears down every tracked project.
+//
+// It is called automatically when a signal is received, but can also be called directly.
+// Returns ErrShutdownTimeout if the processes don't exit in time, or a *ManagerError if any project fails to be torn down.
+func (h *ShutdownHook) Shutdown(sig os.Signal) error {
+	h.mu.Lock()
+
+	running := make([]*runningCmd, 0, len(h.running))
+
+	for r := range h.running {
+		running = append(running, r)
+	}
+
+	projects := make(map[string][]*GlobalOptions, len(h.projects))
+
+	for key, overrides := range h.projects {
+		projects[key] = overrides
+	}
+
+	h.mu.Unlock()
+
+	for _, r := range running {
+		if signaler, ok := r.cmd.(Signaler); ok {
+			signaler.Signal(sig)
+		}
+	}
+
+	timeout := time.After(h.opts.Timeout)
+
+	for _, r := range running {
+		select {
+		case <-r.done:
+		case <-timeout:
+			return ErrShutdownTimeout
+		}
+	}
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs = map[string]error{}
+	)
+
+	for key, overrides := range projects {
+		wg.Add(1)
+
+		go func(key string, overrides []*GlobalOptions) {
+			defer wg.Done()
+
+			ch, err := h.client.Down(h.opts.Down, h.opts.Writer, overrides...)
+
+			if err == nil {
+				err = <-ch
+			}
+
+			if err != nil {
+				mu.Lock()
+				errs[key] = err
+				mu.Unlock()
+			}
+		}(key, overrides)
+	}
+
+	done := make(chan struct{})
+
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(h.opts.Timeout):
+		return ErrShutdownTimeout
+	}
+
+	if len(errs) > 0 {
+		return &ManagerError{Errors: errs}
+	}
+
+	return nil
+}
+
+// track records cmd as running until ch emits, returning a channel that forwards ch
+func (h *ShutdownHook) track(cmd Cmd, ch <-chan error) <-chan error {
+	r := &runningCmd{
+		cmd:  cmd,
+		done: make(chan struct{}),
+	}
+
+	h.mu.Lock()
+	h.running[r] = struct{}{}
+	h.mu.Unlock()
+
+	out := make(chan error)
+
+	go func() {
+		defer close(out)
+
+		err := <-ch
+
+		h.mu.Lock()
+		delete(h.running, r)
+		h.mu.Unlock()
+
+		close(r.done)
+
+		out <- err
+	}()
+
+	return out
+}
+
+// projectKey identifies a project by the global flags used to run its commands
+func (h *ShutdownHook) projectKey(overrides []*GlobalOptions) string {
+	dockerFlags, flags, _ := h.client.globalFlags(overrides...)
+
+	return strings.TrimSpace(dockerFlags + " compose" + flags)
+}
+
+// trackProject records that the project identified by overrides has been brought up
+func (h *ShutdownHook) trackProject(overrides []*GlobalOptions) {
+	key := h.projectKey(overrides)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.projects[key] = overrides
+}
+
+// untrackProject returns a channel that forwards ch, and stops tracking the project once ch emits successfully
+func (h *ShutdownHook) untrackProject(overrides []*GlobalOptions, ch <-chan error) <-chan error {
+	key := h.projectKey(overrides)
+	out := make(chan error)
+
+	go func() {
+		defer close(out)
+
+		err := <-ch
+
+		if err == nil {
+			h.mu.Lock()
+			delete(h.projects, key)
+			h.mu.Unlock()
+		}
+
+		out <- err
+	}()
+
+	return out
+}
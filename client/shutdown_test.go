@@ -0,0 +1,231 @@
+package client_test
+
+import (
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/harrim91/docker-compose-go/client"
+)
+
+// signalCmd blocks attached `up` commands until they are signalled, and completes any other command immediately
+type signalCmd struct {
+	recorder *signalRecorder
+	signal   chan os.Signal
+}
+
+type signalRecorder struct {
+	mu       sync.Mutex
+	commands []string
+	signals  []os.Signal
+}
+
+func (r *signalRecorder) newCmd() client.Cmd {
+	return &signalCmd{
+		recorder: r,
+		signal:   make(chan os.Signal, 1),
+	}
+}
+
+func (r *signalRecorder) Commands() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]string(nil), r.commands...)
+}
+
+func (o *signalCmd) SetStdin(stdin io.Reader) {}
+
+func (o *signalCmd) SetStdout(stdout io.Writer) {}
+
+func (o *signalCmd) SetStderr(stderr io.Writer) {}
+
+func (o *signalCmd) Signal(sig os.Signal) error {
+	o.recorder.mu.Lock()
+	o.recorder.signals = append(o.recorder.signals, sig)
+	o.recorder.mu.Unlock()
+
+	o.signal <- sig
+
+	return nil
+}
+
+func (o *signalCmd) Run(cmd string) (<-chan error, error) {
+	o.recorder.mu.Lock()
+	o.recorder.commands = append(o.recorder.commands, cmd)
+	o.recorder.mu.Unlock()
+
+	ch := make(chan error)
+
+	go func() {
+		if strings.Contains(cmd, " up") && !strings.Contains(cmd, "--detach") {
+			ch <- errors.New("signal: " + (<-o.signal).String())
+			return
+		}
+
+		if strings.Contains(cmd, processErrFlag) {
+			ch <- errors.New(processErrFlag)
+			return
+		}
+
+		ch <- nil
+	}()
+
+	return ch, nil
+}
+
+func TestShutdownHook(t *testing.T) {
+	recorder := &signalRecorder{}
+
+	c := &client.ComposeClient{
+		NewCmd: recorder.newCmd,
+	}
+
+	hook := c.EnableShutdownHook(&client.ShutdownOptions{
+		Down: &client.DownOptions{Volumes: true},
+	})
+
+	defer hook.Stop()
+
+	upCh, err := c.Up(nil, nil, &client.GlobalOptions{ProjectName: "a"})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A detached project that has already been brought up
+	detachedCh, _ := c.Up(&client.UpOptions{Detach: true}, nil, &client.GlobalOptions{ProjectName: "b"})
+
+	if hook.Projects() != 2 {
+		t.Errorf("expected 2 tracked projects, got %d", hook.Projects())
+	}
+
+	done := make(chan error)
+
+	go func() {
+		done <- hook.Shutdown(syscall.SIGINT)
+	}()
+
+	if err := <-upCh; err == nil || err.Error() != "signal: interrupt" {
+		t.Errorf("expected up to be interrupted, got %v", err)
+	}
+
+	<-detachedCh
+
+	if err := <-done; err != nil {
+		t.Error(err)
+	}
+
+	expected := map[string]bool{
+		"docker compose --project-name a down --volumes": true,
+		"docker compose --project-name b down --volumes": true,
+	}
+
+	for _, command := range recorder.Commands() {
+		delete(expected, command)
+	}
+
+	if len(expected) != 0 {
+		t.Errorf("expected down to run for every project, missing %v", expected)
+	}
+
+	if hook.Projects() != 0 {
+		t.Errorf("expected no tracked projects after teardown, got %d", hook.Projects())
+	}
+}
+
+func TestShutdownHookDownUntracksProject(t *testing.T) {
+	recorder := &signalRecorder{}
+
+	c := &client.ComposeClient{
+		NewCmd: recorder.newCmd,
+	}
+
+	hook := c.EnableShutdownHook(nil)
+
+	defer hook.Stop()
+
+	upCh, _ := c.Up(&client.UpOptions{Detach: true}, nil, &client.GlobalOptions{ProjectName: "a"})
+
+	<-upCh
+
+	if hook.Projects() != 1 {
+		t.Errorf("expected 1 tracked project, got %d", hook.Projects())
+	}
+
+	downCh, _ := c.Down(nil, nil, &client.GlobalOptions{ProjectName: "a"})
+
+	if err := <-downCh; err != nil {
+		t.Fatal(err)
+	}
+
+	if hook.Projects() != 0 {
+		t.Errorf("expected project to be untracked after down, got %d", hook.Projects())
+	}
+}
+
+func TestShutdownHookDownError(t *testing.T) {
+	recorder := &signalRecorder{}
+
+	c := &client.ComposeClient{
+		NewCmd: recorder.newCmd,
+	}
+
+	hook := c.EnableShutdownHook(nil)
+
+	defer hook.Stop()
+
+	upCh, _ := c.UpWithResult(nil, nil, &client.GlobalOptions{ProjectName: processErrFlag})
+
+	done := make(chan error)
+
+	go func() {
+		done <- hook.Shutdown(syscall.SIGTERM)
+	}()
+
+	<-upCh
+
+	err := <-done
+
+	var managerErr *client.ManagerError
+
+	if !errors.As(err, &managerErr) || len(managerErr.Errors) != 1 {
+		t.Errorf("expected a ManagerError, got %v", err)
+	}
+
+	if hook.Projects() != 1 {
+		t.Errorf("expected failed project to remain tracked, got %d", hook.Projects())
+	}
+}
+
+func TestShutdownHookTimeout(t *testing.T) {
+	c := &client.ComposeClient{
+		NewCmd: func() client.Cmd {
+			// blockingCmd doesn't implement Signaler, so `up` is never interrupted
+			return &blockingCmd{
+				running:  new(int32),
+				peak:     new(int32),
+				release:  make(chan struct{}),
+				mu:       &sync.Mutex{},
+				commands: &[]string{},
+			}
+		},
+	}
+
+	hook := c.EnableShutdownHook(&client.ShutdownOptions{
+		Timeout: 10 * time.Millisecond,
+	})
+
+	defer hook.Stop()
+
+	c.Up(nil, nil)
+
+	if err := hook.Shutdown(syscall.SIGTERM); !errors.Is(err, client.ErrShutdownTimeout) {
+		t.Errorf("expected ErrShutdownTimeout, got %v", err)
+	}
+}
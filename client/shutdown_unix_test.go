@@ -0,0 +1,42 @@
+//go:build linux || darwin
+
+package client_test
+
+import (
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/harrim91/docker-compose-go/client"
+)
+
+func TestShutdownHookSignal(t *testing.T) {
+	recorder := &signalRecorder{}
+
+	c := &client.ComposeClient{
+		NewCmd: recorder.newCmd,
+	}
+
+	exit := make(chan int)
+
+	hook := c.EnableShutdownHook(&client.ShutdownOptions{
+		Signals: []os.Signal{syscall.SIGUSR1},
+		Exit: func(code int) {
+			exit <- code
+		},
+	})
+
+	defer hook.Stop()
+
+	syscall.Kill(os.Getpid(), syscall.SIGUSR1)
+
+	select {
+	case code := <-exit:
+		if code != 128+int(syscall.SIGUSR1) {
+			t.Errorf("expected exit code %d, got %d", 128+int(syscall.SIGUSR1), code)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("expected the hook to exit after receiving a signal")
+	}
+}
@@ -34,5 +34,16 @@ func startFlags(opts *StartOptions) string {
 //
 // https://docs.docker.com/compose/reference/start/
 func (c *ComposeClient) Start(opts *StartOptions, w io.Writer, overrides ...*GlobalOptions) (<-chan error, error) {
-	return c.RunCommand("start", startFlags(opts), nil, w, overrides...)
+	return processErrors(c.StartProcess(opts, w, overrides...))
+}
+
+// docker compose start
+//
+// Start services, returning a Process handle
+//
+// stderr is written to the given io.Writer
+//
+// https://docs.docker.com/compose/reference/start/
+func (c *ComposeClient) StartProcess(opts *StartOptions, w io.Writer, overrides ...*GlobalOptions) (*Process, error) {
+	return c.RunProcess("start", startFlags(opts), nil, w, overrides...)
 }
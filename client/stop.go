@@ -41,5 +41,16 @@ func stopFlags(opts *StopOptions) string {
 //
 // https://docs.docker.com/compose/reference/stop/
 func (c *ComposeClient) Stop(opts *StopOptions, w io.Writer, overrides ...*GlobalOptions) (<-chan error, error) {
-	return c.RunCommand("stop", stopFlags(opts), nil, w, overrides...)
+	return processErrors(c.StopProcess(opts, w, overrides...))
+}
+
+// docker compose stop
+//
+// Stop services, returning a Process handle
+//
+// stderr is written to the given io.Writer
+//
+// https://docs.docker.com/compose/reference/stop/
+func (c *ComposeClient) StopProcess(opts *StopOptions, w io.Writer, overrides ...*GlobalOptions) (*Process, error) {
+	return c.RunProcess("stop", stopFlags(opts), nil, w, overrides...)
 }
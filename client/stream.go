@@ -0,0 +1,95 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+)
+
+// StreamQuery executes the given docker compose query, decoding each JSON value in stdout into a T as it is written.
+//
+// This suits commands that output a JSON object per line (NDJSON), e.g. `ps --format json` on large projects, or
+// `events --json`, which runs until it is stopped. A JSON array is decoded as a single value.
+//
+//	ctx, cancel := context.WithCancel(context.Background())
+//	values, errCh, err := client.StreamQuery[map[string]interface{}](ctx, c, "events", "--json")
+//
+// Cancelling ctx interrupts the command, and the error channel emits ctx.Err() if the command then fails. The Cmd
+// must implement Signaler for the command to be interrupted.
+//
+// The values channel must be drained until ctx is cancelled. It is closed once the command has completed, before the
+// error channel emits. If the output can't be decoded, the rest of it is discarded and the error channel emits the
+// decoding error, unless the command itself failed.
+func StreamQuery[T any](ctx context.Context, client *ComposeClient, command, flags string, overrides ...*GlobalOptions) (<-chan T, <-chan error, error) {
+	pr, pw := io.Pipe()
+
+	p, err := client.RunProcess(command, flags, pw, nil, overrides...)
+
+	if err != nil {
+		pw.Close()
+		return nil, nil, err
+	}
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			p.Signal(os.Interrupt)
+		case <-p.Done():
+		}
+	}()
+
+	values := make(chan T)
+	decoded := make(chan error, 1)
+
+	go func() {
+		defer close(decoded)
+		defer close(values)
+
+		decoder := json.NewDecoder(pr)
+
+	decode:
+		for {
+			var value T
+
+			if err := decoder.Decode(&value); err != nil {
+				if err != io.EOF {
+					decoded <- err
+				}
+
+				break
+			}
+
+			select {
+			case values <- value:
+			case <-ctx.Done():
+				break decode
+			}
+		}
+
+		// Keep the pipe drained if decoding stopped early, so the command never blocks on a write
+		io.Copy(io.Discard, pr)
+	}()
+
+	errCh := make(chan error)
+
+	go func() {
+		defer close(errCh)
+
+		err := p.Wait(context.Background())
+
+		pw.Close()
+
+		if decodeErr := <-decoded; err == nil {
+			err = decodeErr
+		}
+
+		if err != nil && ctx.Err() != nil {
+			err = ctx.Err()
+		}
+
+		errCh <- err
+	}()
+
+	return values, errCh, nil
+}
@@ -0,0 +1,159 @@
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/harrim91/docker-compose-go/client"
+	"github.com/stretchr/testify/mock"
+)
+
+type streamContainer struct {
+	Name    string
+	Service string
+}
+
+func TestRunQueryExactOutput(t *testing.T) {
+	output := "{\n  \"name\": \"app\"\n}\nweb\n\ndb\n"
+
+	c := newQueryClient(map[string]string{
+		"docker compose foo bar": output,
+	})
+
+	res, err := c.RunQuery("foo", "bar")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(res) != output {
+		t.Errorf("expected %q, got %q", output, res)
+	}
+}
+
+func TestStreamQuery(t *testing.T) {
+	c := newQueryClient(map[string]string{
+		"docker compose ps --format json": "{\"Name\":\"app-web-1\",\"Service\":\"web\"}\n{\"Name\":\"app-db-1\",\"Service\":\"db\"}\n",
+	})
+
+	values, errCh, err := client.StreamQuery[streamContainer](context.Background(), c, "ps", "--format json")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var containers []streamContainer
+
+	for container := range values {
+		containers = append(containers, container)
+	}
+
+	if err := <-errCh; err != nil {
+		t.Error(err)
+	}
+
+	expected := []streamContainer{
+		{Name: "app-web-1", Service: "web"},
+		{Name: "app-db-1", Service: "db"},
+	}
+
+	if !reflect.DeepEqual(containers, expected) {
+		t.Errorf("expected %+v, got %+v", expected, containers)
+	}
+}
+
+func TestStreamQueryDecodeError(t *testing.T) {
+	c := newQueryClient(map[string]string{
+		"docker compose events --json": "{\"action\":\"start\"}\nnot json\n{\"action\":\"stop\"}\n",
+	})
+
+	values, errCh, err := client.StreamQuery[json.RawMessage](context.Background(), c, "events", "--json")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var count int
+
+	for range values {
+		count++
+	}
+
+	if count != 1 {
+		t.Errorf("expected 1 value before the decoding error, got %d", count)
+	}
+
+	if err := <-errCh; err == nil {
+		t.Error("expected a decoding error")
+	}
+}
+
+func TestStreamQueryProcessError(t *testing.T) {
+	cmd := &MockCmd{}
+
+	c := &client.ComposeClient{
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	cmd.On("SetStdout", mock.Anything)
+	cmd.On("Run", mock.Anything)
+
+	values, errCh, err := client.StreamQuery[json.RawMessage](context.Background(), c, errCommand, processErrFlag)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for range values {
+	}
+
+	// The mock writes plain text, but the process error takes precedence over the decoding error
+	if err := <-errCh; err == nil || err.Error() != processErrFlag {
+		t.Errorf("expected error %s, got %v", processErrFlag, err)
+	}
+
+	if _, _, err := client.StreamQuery[json.RawMessage](context.Background(), c, errCommand, runErrFlag); err == nil || err.Error() != runErrFlag {
+		t.Errorf("expected error %s, got %v", runErrFlag, err)
+	}
+}
+
+func TestStreamQueryCancel(t *testing.T) {
+	cmd := &processCmd{signal: make(chan os.Signal, 1)}
+
+	c := &client.ComposeClient{
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	values, errCh, err := client.StreamQuery[json.RawMessage](ctx, c, "events", "--json")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cancel()
+
+	// processCmd only completes once it has been signalled
+	select {
+	case err := <-errCh:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected %v, got %v", context.Canceled, err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected the command to stop once the context was cancelled")
+	}
+
+	if _, ok := <-values; ok {
+		t.Error("expected the values channel to be closed")
+	}
+}
@@ -0,0 +1,360 @@
+package client
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/harrim91/docker-compose-go/compose"
+	"gopkg.in/yaml.v3"
+)
+
+// TestOverride builds an override file that adapts a project for tests, e.g. so several copies of it can run at
+// once. It should be created with `NewTestOverride` or `ComposeClient.TestOverride`.
+//
+//	o, err := c.TestOverride()
+//	o.StripPorts().TmpfsVolumes().Environment(map[string]string{"LOG_LEVEL": "debug"}).ImageTag("pr-123", "web")
+//	err = o.Apply(c)
+//
+// Each method applies to the named services, or every service if none are named. The override is passed after the
+// project's compose files, so only the fields it changes are written.
+type TestOverride struct {
+	project  *compose.Project
+	services map[string]*serviceOverride
+	err      error
+
+	// The compose version the override is written for, which must support the tags it uses
+	compat compat
+}
+
+// The name of the override file in GlobalOptions.InlineFiles
+const testOverrideFileName = "compose.test.yaml"
+
+// serviceOverride is the fields of a service that are overridden
+type serviceOverride struct {
+	image       string
+	environment map[string]string
+	stripPorts  bool
+
+	// The complete list of mounts, replacing the project's. Nil if the mounts aren't overridden.
+	volumes []compose.ServiceVolume
+}
+
+// NewTestOverride returns a TestOverride for the project, e.g. from `ComposeClient.ConfigProject`
+func NewTestOverride(p *compose.Project) *TestOverride {
+	return &TestOverride{
+		project:  p,
+		services: map[string]*serviceOverride{},
+	}
+}
+
+// Returns a TestOverride for the project, as resolved by `docker compose config`.
+//
+// Inline files supplied as a Reader are read into Content, so the same options can be passed to the commands the override is used with.
+func (c *ComposeClient) TestOverride(overrides ...*GlobalOptions) (*TestOverride, error) {
+	if err := c.bufferInlineFiles(overrides...); err != nil {
+		return nil, err
+	}
+
+	p, err := c.ConfigProject(nil, overrides...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	o := NewTestOverride(p)
+	o.compat = c.compat()
+
+	return o, nil
+}
+
+// each calls fn with each of the named services, or every service if none are named, sorted by name.
+// Naming a service that isn't in the project is an error, returned by YAML.
+func (o *TestOverride) each(services []string, fn func(name string, s *compose.Service, override *serviceOverride)) {
+	if len(services) == 0 {
+		services = o.project.ServiceNames()
+	}
+
+	for _, name := range services {
+		s := o.project.Services[name]
+
+		if s == nil {
+			if o.err == nil {
+				o.err = fmt.Errorf("service %q is not in the project", name)
+			}
+
+			continue
+		}
+
+		override, ok := o.services[name]
+
+		if !ok {
+			override = &serviceOverride{}
+			o.services[name] = override
+		}
+
+		fn(name, s, override)
+	}
+}
+
+// StripPorts removes the ports the services publish on the host, so they can't collide with other projects.
+// The services are still reachable from other services in the project.
+//
+// Requires compose 2.24.0 or later, which supports the `!reset` tag. If the client's ComposeVersion is older, YAML
+// returns an UnsupportedFlagError.
+func (o *TestOverride) StripPorts(services ...string) *TestOverride {
+	o.each(services, func(name string, s *compose.Service, override *serviceOverride) {
+		if len(s.Ports) > 0 {
+			override.stripPorts = true
+		}
+	})
+
+	return o
+}
+
+// TmpfsVolumes replaces the named volumes the services mount with tmpfs mounts, so no data persists between runs
+// or is shared with other projects. Bind mounts and anonymous volumes are kept.
+//
+// Requires compose 2.24.4 or later, which supports the `!override` tag. If the client's ComposeVersion is older, YAML
+// returns an UnsupportedFlagError.
+func (o *TestOverride) TmpfsVolumes(services ...string) *TestOverride {
+	o.each(services, func(name string, s *compose.Service, override *serviceOverride) {
+		volumes := override.volumes
+
+		if volumes == nil {
+			volumes = s.Volumes
+		}
+
+		replaced := false
+		mounts := make([]compose.ServiceVolume, 0, len(volumes))
+
+		for _, volume := range volumes {
+			if volume.Type == compose.VolumeTypeVolume && volume.Source != "" {
+				volume = compose.ServiceVolume{Type: compose.VolumeTypeTmpfs, Target: volume.Target}
+				replaced = true
+			}
+
+			mounts = append(mounts, volume)
+		}
+
+		if replaced {
+			override.volumes = mounts
+		}
+	})
+
+	return o
+}
+
+// Environment sets environment variables in the services' containers, replacing any the project sets
+func (o *TestOverride) Environment(env map[string]string, services ...string) *TestOverride {
+	o.each(services, func(name string, s *compose.Service, override *serviceOverride) {
+		if len(env) == 0 {
+			return
+		}
+
+		if override.environment == nil {
+			override.environment = map[string]string{}
+		}
+
+		for key, value := range env {
+			override.environment[key] = value
+		}
+	})
+
+	return o
+}
+
+// ImageTag changes the tag of the services' images, e.g. to test the image built for a pull request. A digest the
+// image is pinned to is removed. Services without an image, which are only built, are left unchanged.
+func (o *TestOverride) ImageTag(tag string, services ...string) *TestOverride {
+	o.each(services, func(name string, s *compose.Service, override *serviceOverride) {
+		if s.Image != "" {
+			override.image = withTag(s.Image, tag)
+		}
+	})
+
+	return o
+}
+
+// withTag returns the image with its tag and digest replaced by tag
+func withTag(image, tag string) string {
+	image, _, _ = strings.Cut(image, "@")
+
+	// The tag follows the last colon after the last slash, which may be a registry port otherwise
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image = image[:i]
+	}
+
+	return image + ":" + tag
+}
+
+// YAML returns the override file, e.g.
+//
+//	services:
+//	  web:
+//	    environment:
+//	      LOG_LEVEL: debug
+//	    image: my/web:pr-123
+//	    ports: !reset []
+func (o *TestOverride) YAML() ([]byte, error) {
+	if o.err != nil {
+		return nil, o.err
+	}
+
+	if err := o.requireTags(o.compat); err != nil {
+		return nil, err
+	}
+
+	services := &yaml.Node{Kind: yaml.MappingNode}
+
+	for _, name := range sortedKeys(o.services) {
+		override := o.services[name]
+		service := &yaml.Node{Kind: yaml.MappingNode}
+
+		if len(override.environment) > 0 {
+			environment := &yaml.Node{Kind: yaml.MappingNode}
+
+			for _, key := range sortedKeys(override.environment) {
+				if err := appendField(environment, key, override.environment[key], ""); err != nil {
+					return nil, err
+				}
+			}
+
+			service.Content = append(service.Content, scalarNode("environment"), environment)
+		}
+
+		if override.image != "" {
+			if err := appendField(service, "image", override.image, ""); err != nil {
+				return nil, err
+			}
+		}
+
+		if override.stripPorts {
+			service.Content = append(service.Content, scalarNode("ports"), &yaml.Node{
+				Kind:  yaml.SequenceNode,
+				Tag:   "!reset",
+				Style: yaml.FlowStyle,
+			})
+		}
+
+		if override.volumes != nil {
+			if err := appendField(service, "volumes", override.volumes, "!override"); err != nil {
+				return nil, err
+			}
+		}
+
+		if len(service.Content) > 0 {
+			services.Content = append(services.Content, scalarNode(name), service)
+		}
+	}
+
+	if len(services.Content) == 0 {
+		services.Style = yaml.FlowStyle
+	}
+
+	root := &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{scalarNode("services"), services}}
+
+	var buff bytes.Buffer
+
+	enc := yaml.NewEncoder(&buff)
+	enc.SetIndent(2)
+
+	if err := enc.Encode(root); err != nil {
+		return nil, err
+	}
+
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+
+	return buff.Bytes(), nil
+}
+
+// requireTags returns an UnsupportedFlagError if the override uses a tag the compose version doesn't support
+func (o *TestOverride) requireTags(compat compat) error {
+	for _, name := range sortedKeys(o.services) {
+		override := o.services[name]
+
+		if override.stripPorts {
+			if err := compat.require("!reset", "2.24.0"); err != nil {
+				return err
+			}
+		}
+
+		if override.volumes != nil {
+			if err := compat.require("!override", "2.24.4"); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func scalarNode(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Value: value}
+}
+
+// appendField appends a key and its encoded value to a mapping node, tagging the value if tag isn't empty
+func appendField(mapping *yaml.Node, key string, value interface{}, tag string) error {
+	b, err := yaml.Marshal(value)
+
+	if err != nil {
+		return err
+	}
+
+	var doc yaml.Node
+
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return err
+	}
+
+	node := doc.Content[0]
+
+	if tag != "" {
+		node.Tag = tag
+	}
+
+	mapping.Content = append(mapping.Content, scalarNode(key), node)
+
+	return nil
+}
+
+// Options returns options that apply the override file on top of the project's compose files. These can be passed
+// as an override to any command, e.g. `c.Up(opts, w, testOptions)`.
+func (o *TestOverride) Options() (*GlobalOptions, error) {
+	content, err := o.YAML()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &GlobalOptions{
+		InlineFiles: []ComposeFile{
+			{Name: testOverrideFileName, Content: content, Override: true},
+		},
+	}, nil
+}
+
+// Apply adds the override file to the client's options, so it applies to every command the client runs. An override
+// file applied before is replaced.
+func (o *TestOverride) Apply(c *ComposeClient) error {
+	if err := o.requireTags(c.compat()); err != nil {
+		return err
+	}
+
+	opts, err := o.Options()
+
+	if err != nil {
+		return err
+	}
+
+	previous := &GlobalOptions{
+		InlineFiles:      []ComposeFile{{Name: testOverrideFileName}},
+		InlineFilesMerge: ListMergeRemove,
+	}
+
+	c.GlobalOptions = c.GlobalOptions.Merge(previous, opts)
+
+	return nil
+}
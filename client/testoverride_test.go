@@ -0,0 +1,191 @@
+package client_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/harrim91/docker-compose-go/client"
+	"github.com/harrim91/docker-compose-go/compose"
+)
+
+const testOverrideConfig = `{"name":"app","services":{
+	"web":{
+		"image":"registry.local:5000/my/web:1.2@sha256:aaa",
+		"ports":[{"target":80,"published":"8080"}],
+		"volumes":[
+			{"type":"bind","source":"/src","target":"/app","consistency":"cached","bind":{"propagation":"rshared","create_host_path":true,"selinux":"z"}},
+			{"type":"volume","source":"uploads","target":"/uploads"}
+		]
+	},
+	"db":{
+		"image":"postgres",
+		"ports":[{"target":5432,"published":"5432"}],
+		"volumes":[{"type":"volume","source":"db-data","target":"/var/lib/postgresql/data","read_only":true}]
+	},
+	"worker":{"build":{"context":"."}}
+},"volumes":{"db-data":{},"uploads":{}}}`
+
+func TestTestOverride(t *testing.T) {
+	p, err := compose.ParseJSON([]byte(testOverrideConfig))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	o := client.NewTestOverride(p).
+		StripPorts().
+		TmpfsVolumes().
+		Environment(map[string]string{"LOG_LEVEL": "debug", "WORKERS": "1"}, "web", "worker").
+		ImageTag("pr-123")
+
+	content, err := o.YAML()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `services:
+  db:
+    image: postgres:pr-123
+    ports: !reset []
+    volumes: !override
+      - type: tmpfs
+        target: /var/lib/postgresql/data
+  web:
+    environment:
+      LOG_LEVEL: debug
+      WORKERS: "1"
+    image: registry.local:5000/my/web:pr-123
+    ports: !reset []
+    volumes: !override
+      - type: bind
+        source: /src
+        target: /app
+        consistency: cached
+        bind:
+          propagation: rshared
+          create_host_path: true
+          selinux: z
+      - type: tmpfs
+        target: /uploads
+  worker:
+    environment:
+      LOG_LEVEL: debug
+      WORKERS: "1"
+`
+
+	if string(content) != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, content)
+	}
+}
+
+func TestTestOverrideEmpty(t *testing.T) {
+	p, _ := compose.ParseJSON([]byte(testOverrideConfig))
+
+	content, err := client.NewTestOverride(p).StripPorts("worker").YAML()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(content) != "services: {}\n" {
+		t.Errorf("expected no services, got:\n%s", content)
+	}
+}
+
+func TestTestOverrideUnknownService(t *testing.T) {
+	p, _ := compose.ParseJSON([]byte(testOverrideConfig))
+
+	if _, err := client.NewTestOverride(p).StripPorts("cache").Options(); err == nil {
+		t.Error("expected an error for a service that isn't in the project")
+	}
+}
+
+func TestTestOverrideApply(t *testing.T) {
+	c := newQueryClient(map[string]string{
+		"docker compose --file compose.yaml config --format json": testOverrideConfig,
+	})
+
+	c.GlobalOptions = &client.GlobalOptions{Files: []string{"compose.yaml"}}
+
+	o, err := c.TestOverride()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := o.StripPorts("db").Apply(c); err != nil {
+		t.Fatal(err)
+	}
+
+	files := c.GlobalOptions.InlineFiles
+
+	if len(files) != 1 || !files[0].Override || string(files[0].Content) != "services:\n  db:\n    ports: !reset []\n" {
+		t.Errorf("expected the override in the client's inline files, got %+v", files)
+	}
+
+	if len(c.GlobalOptions.Files) != 1 {
+		t.Errorf("expected the client's files to be kept, got %v", c.GlobalOptions.Files)
+	}
+
+	if err := o.StripPorts("web").Apply(c); err != nil {
+		t.Fatal(err)
+	}
+
+	files = c.GlobalOptions.InlineFiles
+
+	if len(files) != 1 || string(files[0].Content) != "services:\n  db:\n    ports: !reset []\n  web:\n    ports: !reset []\n" {
+		t.Errorf("expected the override to be replaced when applied again, got %+v", files)
+	}
+}
+
+func TestTestOverrideUnsupportedTags(t *testing.T) {
+	c := newQueryClient(map[string]string{
+		"docker compose config --format json": testOverrideConfig,
+	})
+
+	tests := []struct {
+		version string
+		flag    string
+	}{
+		{"2.23.3", "!reset"},
+		{"2.24.3", "!override"},
+	}
+
+	for _, test := range tests {
+		c.ComposeVersion = test.version
+
+		o, err := c.TestOverride()
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = o.StripPorts().TmpfsVolumes().YAML()
+
+		var unsupported *client.UnsupportedFlagError
+
+		if !errors.As(err, &unsupported) || unsupported.Flag != test.flag {
+			t.Errorf("expected an UnsupportedFlagError for %s with compose %s, got %v", test.flag, test.version, err)
+		}
+	}
+
+	c.ComposeVersion = "2.24.4"
+
+	o, err := c.TestOverride()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := o.StripPorts().TmpfsVolumes().YAML(); err != nil {
+		t.Errorf("expected the tags to be supported by compose 2.24.4, got %v", err)
+	}
+
+	// Apply checks the tags against the version of the client it is applied to
+	c.ComposeVersion = "2.23.3"
+
+	if err := o.Apply(c); err == nil {
+		t.Error("expected an error applying the override to an older version of compose")
+	}
+}
@@ -7,8 +7,26 @@ import (
 	"strings"
 )
 
+type PullPolicyFlag string
+
+const (
+	// Always pull images before starting containers.
+	PullPolicyFlagAlways PullPolicyFlag = "always"
+
+	// Only pull images that are missing locally.
+	PullPolicyFlagMissing PullPolicyFlag = "missing"
+
+	// Never pull images. Starting fails if an image is missing.
+	PullPolicyFlagNever PullPolicyFlag = "never"
+
+	// Build images rather than pulling them, for services that have a build section.
+	PullPolicyFlagBuild PullPolicyFlag = "build"
+)
+
 // UpOptions represents the command line options for the `docker compose up` command.
 //
+// Options that require a newer version than the client's ComposeVersion return an UnsupportedFlagError.
+//
 // https://docs.docker.com/compose/reference/up/
 type UpOptions struct {
 	// Detached mode: Run containers in the background, print new container names. Detached mode is incompatible with --abort-on-container-exit.
@@ -44,9 +62,42 @@ type UpOptions struct {
 	// Stops all containers if any container was stopped. Incompatible with --detach.
 	AbortOnContainerExit bool
 
+	// Stops all containers if any container exited with failure. Incompatible with --detach. Requires docker compose 2.27.0 or later.
+	AbortOnContainerFailure bool
+
+	// Restrict attaching to the specified services. Incompatible with --attach-dependencies. Requires docker compose 2.11.0 or later.
+	Attach []string
+
 	// Attach to dependent containers.
 	AttachDependencies bool
 
+	// Do not attach (stream logs) to the specified services. Requires docker compose 2.11.0 or later.
+	NoAttach []string
+
+	// Don't prefix each log line with the service name.
+	NoLogPrefix bool
+
+	// Show timestamps.
+	Timestamps bool
+
+	// Pull images before running. Requires docker compose 2.3.0 or later.
+	Pull PullPolicyFlag
+
+	// Wait for services to be running|healthy. Implies --detach. Requires docker compose 2.1.1 or later.
+	Wait bool
+
+	// Maximum duration in seconds to wait for the project to be running|healthy. Requires docker compose 2.17.0 or later.
+	WaitTimeout *int
+
+	// Enable or disable the interactive shortcuts menu when attached. Requires docker compose 2.26.0 or later.
+	Menu *bool
+
+	// Watch source code and rebuild/refresh containers when files are updated. Requires docker compose 2.22.0 or later.
+	Watch bool
+
+	// Assume "yes" as the answer to all prompts and run non-interactively. Requires docker compose 2.26.0 or later.
+	Yes bool
+
 	// Use this timeout in seconds for container shutdown when attached or when containers are already running. (default: 10)
 	Timeout *int
 
@@ -66,7 +117,7 @@ type UpOptions struct {
 	Services []string
 }
 
-func upFlags(opts *UpOptions) string {
+func upFlags(opts *UpOptions, compat compat) (string, error) {
 	flags := ""
 
 	if opts != nil {
@@ -114,10 +165,94 @@ func upFlags(opts *UpOptions) string {
 			flags = fmt.Sprintf("%s --abort-on-container-exit", flags)
 		}
 
+		if opts.AbortOnContainerFailure {
+			if err := compat.require("--abort-on-container-failure", "2.27.0"); err != nil {
+				return "", err
+			}
+
+			flags = fmt.Sprintf("%s --abort-on-container-failure", flags)
+		}
+
+		if len(opts.Attach) > 0 {
+			if err := compat.require("--attach", "2.11.0"); err != nil {
+				return "", err
+			}
+		}
+
+		for _, service := range opts.Attach {
+			flags = fmt.Sprintf("%s --attach %s", flags, service)
+		}
+
 		if opts.AttachDependencies {
 			flags = fmt.Sprintf("%s --attach-dependencies", flags)
 		}
 
+		if len(opts.NoAttach) > 0 {
+			if err := compat.require("--no-attach", "2.11.0"); err != nil {
+				return "", err
+			}
+		}
+
+		for _, service := range opts.NoAttach {
+			flags = fmt.Sprintf("%s --no-attach %s", flags, service)
+		}
+
+		if opts.NoLogPrefix {
+			flags = fmt.Sprintf("%s --no-log-prefix", flags)
+		}
+
+		if opts.Timestamps {
+			flags = fmt.Sprintf("%s --timestamps", flags)
+		}
+
+		if opts.Pull != "" {
+			if err := compat.require("--pull", "2.3.0"); err != nil {
+				return "", err
+			}
+
+			flags = fmt.Sprintf("%s --pull %s", flags, opts.Pull)
+		}
+
+		if opts.Wait {
+			if err := compat.require("--wait", "2.1.1"); err != nil {
+				return "", err
+			}
+
+			flags = fmt.Sprintf("%s --wait", flags)
+		}
+
+		if opts.WaitTimeout != nil {
+			if err := compat.require("--wait-timeout", "2.17.0"); err != nil {
+				return "", err
+			}
+
+			flags = fmt.Sprintf("%s --wait-timeout %d", flags, *opts.WaitTimeout)
+		}
+
+		if opts.Menu != nil {
+			if err := compat.require("--menu", "2.26.0"); err != nil {
+				return "", err
+			}
+
+			flags = fmt.Sprintf("%s --menu=%t", flags, *opts.Menu)
+		}
+
+		if opts.Watch {
+			if err := compat.require("--watch", "2.22.0"); err != nil {
+				return "", err
+			}
+
+			flags = fmt.Sprintf("%s --watch", flags)
+		}
+
+		if opts.Yes {
+			if err := compat.require("--yes", "2.26.0"); err != nil {
+				return "", err
+			}
+
+			flags = fmt.Sprintf("%s --yes", flags)
+		}
+
 		if opts.Timeout != nil {
 			flags = fmt.Sprintf("%s --timeout %d", flags, *opts.Timeout)
 		}
@@ -152,7 +287,7 @@ func upFlags(opts *UpOptions) string {
 		}
 	}
 
-	return strings.TrimSpace(flags)
+	return strings.TrimSpace(flags), nil
 }
 
 // docker compose up
@@ -163,5 +298,28 @@ func upFlags(opts *UpOptions) string {
 //
 // https://docs.docker.com/compose/reference/up/
 func (client *ComposeClient) Up(opts *UpOptions, w io.Writer, overrides ...*GlobalOptions) (<-chan error, error) {
-	return client.RunCommand("up", upFlags(opts), nil, w, overrides...)
+	return processErrors(client.UpProcess(opts, w, overrides...))
+}
+
+// docker compose up
+//
+// Builds, (re)creates, starts, and attaches to containers for a service, returning a Process handle.
+//
+// stderr is written to the given io.Writer
+//
+// https://docs.docker.com/compose/reference/up/
+func (client *ComposeClient) UpProcess(opts *UpOptions, w io.Writer, overrides ...*GlobalOptions) (*Process, error) {
+	flags, err := upFlags(opts, client.compat())
+
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := client.RunProcess("up", flags, nil, w, overrides...)
+
+	if shutdown := client.shutdownHook(); err == nil && shutdown != nil {
+		shutdown.trackProject(overrides)
+	}
+
+	return p, err
 }
@@ -0,0 +1,357 @@
+package client
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+type UpState string
+
+const (
+	UpStatePulling   UpState = "Pulling"
+	UpStatePulled    UpState = "Pulled"
+	UpStateCreating  UpState = "Creating"
+	UpStateCreated   UpState = "Created"
+	UpStateRecreate  UpState = "Recreate"
+	UpStateRecreated UpState = "Recreated"
+	UpStateStarting  UpState = "Starting"
+	UpStateStarted   UpState = "Started"
+	UpStateRunning   UpState = "Running"
+	UpStateWaiting   UpState = "Waiting"
+	UpStateHealthy   UpState = "Healthy"
+	UpStateExited    UpState = "Exited"
+	UpStateStopping  UpState = "Stopping"
+	UpStateStopped   UpState = "Stopped"
+	UpStateError     UpState = "Error"
+
+	// Reported when a dependency of another service failed its healthcheck.
+	UpStateUnhealthy UpState = "Unhealthy"
+
+	// Reported when a service's image isn't pulled, e.g. because another service is already pulling it.
+	UpStateSkipped UpState = "Skipped"
+)
+
+// upErrorPrefix prefixes container statuses that carry an error message, e.g. `Error response from daemon: ...`
+const upErrorPrefix = "Error "
+
+var (
+	ansiEscapeRegexp     = regexp.MustCompile(`\x1b\[[0-9;?]*[a-zA-Z]`)
+	upContainerRegexp    = regexp.MustCompile(`^[^A-Za-z]*Container (\S+)\s+(.+)$`)
+	upResourceRegexp     = regexp.MustCompile(`^[^A-Za-z]*(?:Network|Volume|Image) \S+`)
+	upPullRegexp         = regexp.MustCompile(`^[^A-Za-z]*(\S+) (Pulling|Pulled|Skipped|Error)(?:\s+(.*))?$`)
+	upDependencyRegexp   = regexp.MustCompile(`container (\S+) (?:is unhealthy|exited \(\d+\))`)
+	upContainerNameRegex = regexp.MustCompile(`^(.+)[-_](\d+)$`)
+
+	// The elapsed time compose appends to each line when attached to a terminal, e.g. `Started    0.3s`
+	upTimingRegexp = regexp.MustCompile(`\s+[\d.]+s$`)
+)
+
+// UpTransition is a single state change reported by `docker compose up`
+type UpTransition struct {
+	// The container the state change applies to. Empty for service level events such as pulling.
+	Container string
+
+	State UpState
+
+	// When the transition was parsed.
+	Time time.Time
+}
+
+// UpServiceResult is the outcome of `docker compose up` for a single service
+type UpServiceResult struct {
+	Service string
+
+	// The containers created for the service.
+	Containers []string
+
+	// The latest state reported for the service.
+	State UpState
+
+	// Every state change reported for the service, in order.
+	Transitions []UpTransition
+
+	// Error messages reported for the service.
+	Errors []string
+}
+
+// Failed reports whether compose reported an error for the service.
+func (r *UpServiceResult) Failed() bool {
+	return len(r.Errors) > 0 || r.State == UpStateError || r.State == UpStateUnhealthy
+}
+
+// UpResult is emitted once an UpWithResult command has completed
+type UpResult struct {
+	// Per-service outcomes, in the order services were first reported.
+	Services []*UpServiceResult
+
+	// The error returned by the up command, if any
+	Err error
+}
+
+// Service returns the result for the given service, or nil if nothing was reported for it.
+func (r *UpResult) Service(name string) *UpServiceResult {
+	for _, service := range r.Services {
+		if service.Service == name {
+			return service
+		}
+	}
+
+	return nil
+}
+
+// UpProgressParser converts the progress output of `docker compose up` into per-service results.
+type UpProgressParser struct {
+	// The compose project name, used to map container names to services.
+	ProjectName string
+
+	services  map[string]*UpServiceResult
+	order     []string
+	lastError *UpServiceResult
+}
+
+// NewUpProgressParser returns a new UpProgressParser for the given project
+func NewUpProgressParser(projectName string) *UpProgressParser {
+	return &UpProgressParser{
+		ProjectName: projectName,
+		services:    map[string]*UpServiceResult{},
+	}
+}
+
+// Parse reads progress output from r until EOF.
+func (p *UpProgressParser) Parse(r io.Reader) error {
+	reader := bufio.NewReader(r)
+
+	for {
+		line, err := reader.ReadString('\n')
+
+		if len(line) > 0 {
+			p.ParseLine(line)
+		}
+
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// ParseLine parses a single line of progress output.
+func (p *UpProgressParser) ParseLine(line string) {
+	line = strings.TrimSpace(ansiEscapeRegexp.ReplaceAllString(line, ""))
+	line = upTimingRegexp.ReplaceAllString(line, "")
+
+	if line == "" {
+		return
+	}
+
+	if match := upContainerRegexp.FindStringSubmatch(line); match != nil {
+		container := match[1]
+		status := strings.TrimSpace(match[2])
+		result := p.service(p.containerService(container))
+
+		if !containsString(result.Containers, container) {
+			result.Containers = append(result.Containers, container)
+		}
+
+		state := UpState(status)
+
+		if strings.HasPrefix(status, upErrorPrefix) {
+			state = UpStateError
+			result.Errors = append(result.Errors, status)
+		}
+
+		p.transition(result, container, state)
+
+		return
+	}
+
+	if upResourceRegexp.MatchString(line) {
+		return
+	}
+
+	if match := upPullRegexp.FindStringSubmatch(line); match != nil {
+		result := p.service(match[1])
+		state := UpState(match[2])
+
+		if state == UpStateError && match[3] != "" {
+			result.Errors = append(result.Errors, match[3])
+		}
+
+		p.transition(result, "", state)
+
+		return
+	}
+
+	// Remaining error messages are attributed to the container they name, or to the last service that errored.
+	if match := upDependencyRegexp.FindStringSubmatch(line); match != nil {
+		result := p.service(p.containerService(match[1]))
+		result.Errors = append(result.Errors, line)
+
+		if result.State != UpStateError {
+			p.transition(result, match[1], UpStateUnhealthy)
+		}
+
+		return
+	}
+
+	lower := strings.ToLower(line)
+
+	if p.lastError != nil && (strings.Contains(lower, "error") || strings.Contains(lower, "failed")) {
+		p.lastError.Errors = append(p.lastError.Errors, line)
+	}
+}
+
+// Services returns the per-service results parsed so far.
+func (p *UpProgressParser) Services() []*UpServiceResult {
+	services := make([]*UpServiceResult, 0, len(p.order))
+
+	for _, name := range p.order {
+		services = append(services, p.services[name])
+	}
+
+	return services
+}
+
+func (p *UpProgressParser) transition(result *UpServiceResult, container string, state UpState) {
+	result.State = state
+	result.Transitions = append(result.Transitions, UpTransition{
+		Container: container,
+		State:     state,
+		Time:      time.Now(),
+	})
+
+	if state == UpStateError {
+		p.lastError = result
+	}
+}
+
+func (p *UpProgressParser) service(name string) *UpServiceResult {
+	result, ok := p.services[name]
+
+	if !ok {
+		result = &UpServiceResult{Service: name}
+		p.services[name] = result
+		p.order = append(p.order, name)
+	}
+
+	return result
+}
+
+// containerService maps a container name like `project-web-1` (or `project_web_1` for v1) to its service name
+func (p *UpProgressParser) containerService(container string) string {
+	name := container
+
+	if match := upContainerNameRegex.FindStringSubmatch(name); match != nil {
+		name = match[1]
+	}
+
+	if p.ProjectName != "" {
+		for _, sep := range []string{"-", "_"} {
+			if strings.HasPrefix(name, p.ProjectName+sep) {
+				return strings.TrimPrefix(name, p.ProjectName+sep)
+			}
+		}
+	}
+
+	return name
+}
+
+// projectName returns the compose project name, either from the client options or from the resolved Compose config
+func (c *ComposeClient) projectName(overrides ...*GlobalOptions) string {
+	if name := c.GlobalOptions.Merge(overrides...).ProjectName; name != "" {
+		return name
+	}
+
+	res, err := c.RunQuery("config", configFlags(nil), overrides...)
+
+	if err != nil {
+		return ""
+	}
+
+	var config struct {
+		Name string `json:"name"`
+	}
+
+	json.Unmarshal(res, &config)
+
+	return config.Name
+}
+
+// docker compose up
+//
+// Builds, (re)creates, starts, and attaches to containers for a service, returning the outcome for each service.
+//
+// The per-service outcomes are parsed from compose's progress output. The raw output is also written to the given io.Writer.
+//
+// If no ProjectName is set on the client or the overrides, the project name is resolved from the Compose config so that containers can be mapped to services.
+//
+// https://docs.docker.com/compose/reference/up/
+func (client *ComposeClient) UpWithResult(opts *UpOptions, w io.Writer, overrides ...*GlobalOptions) (<-chan UpResult, error) {
+	flags, err := upFlags(opts, client.compat())
+
+	if err != nil {
+		return nil, err
+	}
+
+	// The project name is resolved by a separate command, which would otherwise consume an inline file's Reader
+	if err := client.bufferInlineFiles(overrides...); err != nil {
+		return nil, err
+	}
+
+	parser := NewUpProgressParser(client.projectName(overrides...))
+
+	pr, pw := io.Pipe()
+
+	var stderr io.Writer = pw
+
+	if w != nil {
+		stderr = io.MultiWriter(pw, w)
+	}
+
+	ch, err := client.RunCommand("up", flags, nil, stderr, overrides...)
+
+	if err != nil {
+		pw.Close()
+		return nil, err
+	}
+
+	if shutdown := client.shutdownHook(); shutdown != nil {
+		shutdown.trackProject(overrides)
+	}
+
+	parsed := make(chan struct{})
+
+	go func() {
+		defer close(parsed)
+
+		parser.Parse(pr)
+
+		// Keep the pipe drained if parsing stopped early, so the command never blocks on a write
+		io.Copy(io.Discard, pr)
+	}()
+
+	resultCh := make(chan UpResult)
+
+	go func() {
+		defer close(resultCh)
+
+		err := <-ch
+
+		pw.Close()
+		<-parsed
+
+		resultCh <- UpResult{
+			Services: parser.Services(),
+			Err:      err,
+		}
+	}()
+
+	return resultCh, nil
+}
@@ -0,0 +1,290 @@
+package client_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/harrim91/docker-compose-go/client"
+	"github.com/stretchr/testify/mock"
+)
+
+const (
+	upConfig string = `{"name": "my-app", "services": {}}`
+	upOutput string = ` Network my-app_default  Creating
+ Network my-app_default  Created
+ Container my-app-db-1  Creating
+ Container my-app-web-1  Creating
+ Container my-app-db-1  Created
+ Container my-app-web-1  Created
+ Container my-app-db-1  Starting
+ Container my-app-db-1  Started
+ Container my-app-db-1  Waiting
+ Container my-app-db-1  Healthy
+ Container my-app-web-1  Starting
+ Container my-app-web-1  Error
+Error response from daemon: driver failed programming external connectivity: Bind for 0.0.0.0:80 failed: port is already allocated
+`
+)
+
+type mockUpResultCmd struct {
+	mock.Mock
+	stdout io.Writer
+	stderr io.Writer
+}
+
+func (o *mockUpResultCmd) SetStdin(stdin io.Reader) {
+	o.Called(stdin)
+}
+
+func (o *mockUpResultCmd) SetStdout(stdout io.Writer) {
+	o.Called(stdout)
+	o.stdout = stdout
+}
+
+func (o *mockUpResultCmd) SetStderr(stderr io.Writer) {
+	o.Called(stderr)
+	o.stderr = stderr
+}
+
+func (o *mockUpResultCmd) Run(cmd string) (<-chan error, error) {
+	o.Called(cmd)
+
+	if strings.Contains(cmd, runErrFlag) {
+		return nil, errors.New(runErrFlag)
+	}
+
+	ch := make(chan error)
+
+	go func() {
+		if strings.Contains(cmd, " config ") {
+			o.stdout.Write([]byte(upConfig))
+			ch <- nil
+			return
+		}
+
+		if o.stderr != nil {
+			o.stderr.Write([]byte(upOutput))
+		}
+
+		ch <- errors.New("exit status 1")
+	}()
+
+	return ch, nil
+}
+
+func TestUpProgressParser(t *testing.T) {
+	parser := client.NewUpProgressParser("my-app")
+
+	parser.Parse(strings.NewReader(upOutput))
+
+	services := parser.Services()
+
+	if len(services) != 2 {
+		t.Fatalf("expected 2 services, got %d", len(services))
+	}
+
+	db := services[0]
+
+	if db.Service != "db" || db.State != client.UpStateHealthy || db.Failed() {
+		t.Errorf("unexpected db result: %+v", db)
+	}
+
+	states := []client.UpState{
+		client.UpStateCreating,
+		client.UpStateCreated,
+		client.UpStateStarting,
+		client.UpStateStarted,
+		client.UpStateWaiting,
+		client.UpStateHealthy,
+	}
+
+	if len(db.Transitions) != len(states) {
+		t.Fatalf("expected %d transitions, got %+v", len(states), db.Transitions)
+	}
+
+	for i, state := range states {
+		if db.Transitions[i].State != state || db.Transitions[i].Container != "my-app-db-1" {
+			t.Errorf("transition %d: expected %s, got %+v", i, state, db.Transitions[i])
+		}
+	}
+
+	web := services[1]
+
+	if web.Service != "web" || web.State != client.UpStateError || !web.Failed() {
+		t.Errorf("unexpected web result: %+v", web)
+	}
+
+	if len(web.Errors) != 1 || !strings.Contains(web.Errors[0], "port is already allocated") {
+		t.Errorf("expected port allocation error, got %v", web.Errors)
+	}
+}
+
+func TestUpProgressParserUnhealthyDependency(t *testing.T) {
+	parser := client.NewUpProgressParser("proj")
+
+	parser.ParseLine(" Container proj-db-1  Waiting\n")
+	parser.ParseLine("dependency failed to start: container proj-db-1 is unhealthy\n")
+
+	db := parser.Services()[0]
+
+	if db.State != client.UpStateUnhealthy || len(db.Errors) != 1 {
+		t.Errorf("unexpected db result: %+v", db)
+	}
+}
+
+func TestUpProgressParserPullError(t *testing.T) {
+	parser := client.NewUpProgressParser("proj")
+
+	parser.ParseLine(" web Pulling\n")
+	parser.ParseLine(" web Error\n")
+	parser.ParseLine("Error response from daemon: pull access denied for web\n")
+
+	web := parser.Services()[0]
+
+	if web.State != client.UpStateError || len(web.Errors) != 1 || len(web.Transitions) != 2 {
+		t.Errorf("unexpected web result: %+v", web)
+	}
+}
+
+func TestUpProgressParserTTY(t *testing.T) {
+	parser := client.NewUpProgressParser("proj")
+
+	parser.Parse(strings.NewReader(`[+] Running 4/4
+ ✔ web Skipped - Image is already being pulled by api                   0.0s
+ ✔ api Pulled                                                          3.2s
+ ✔ Container proj-api-1  Started                                       0.3s
+ ✘ Container proj-web-1  Error response from daemon: port is already allocated    0.1s
+`))
+
+	services := parser.Services()
+
+	if len(services) != 2 {
+		t.Fatalf("expected 2 services, got %+v", services)
+	}
+
+	web, api := services[0], services[1]
+
+	if len(web.Transitions) != 2 || web.Transitions[0].State != client.UpStateSkipped {
+		t.Errorf("expected web to be skipped, got %+v", web.Transitions)
+	}
+
+	if web.State != client.UpStateError || len(web.Errors) != 1 || web.Errors[0] != "Error response from daemon: port is already allocated" {
+		t.Errorf("expected the error without its timing, got %+v", web)
+	}
+
+	if api.State != client.UpStateStarted || len(api.Transitions) != 2 || api.Transitions[0].State != client.UpStatePulled {
+		t.Errorf("unexpected api result: %+v", api)
+	}
+}
+
+func TestUpProgressParserIgnoresLogs(t *testing.T) {
+	parser := client.NewUpProgressParser("proj")
+
+	parser.ParseLine(" Container proj-web-1  Error\n")
+	parser.ParseLine("web-1  | listening on :80\n")
+
+	if web := parser.Services()[0]; len(web.Errors) != 0 {
+		t.Errorf("expected no errors, got %v", web.Errors)
+	}
+}
+
+func TestUpWithResult(t *testing.T) {
+	cmd := &mockUpResultCmd{}
+
+	c := &client.ComposeClient{
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	var buff bytes.Buffer
+
+	cmd.On("SetStdout", mock.Anything)
+	cmd.On("SetStderr", mock.Anything)
+	cmd.On("Run", "docker compose config --format json")
+	cmd.On("Run", "docker compose up --detach")
+
+	ch, err := c.UpWithResult(&client.UpOptions{
+		Detach: true,
+	}, &buff)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := <-ch
+
+	cmd.AssertExpectations(t)
+
+	if result.Err == nil {
+		t.Error("expected an error")
+	}
+
+	if buff.String() != upOutput {
+		t.Errorf("expected raw output to be written to the writer, got: %s", buff.String())
+	}
+
+	if web := result.Service("web"); web == nil || !web.Failed() {
+		t.Errorf("expected web to have failed, got %+v", web)
+	}
+
+	if db := result.Service("db"); db == nil || db.Failed() {
+		t.Errorf("expected db to be healthy, got %+v", db)
+	}
+}
+
+func TestUpWithResultProjectName(t *testing.T) {
+	cmd := &mockUpResultCmd{}
+
+	c := &client.ComposeClient{
+		GlobalOptions: &client.GlobalOptions{
+			ProjectName: "my-app",
+		},
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	cmd.On("SetStderr", mock.Anything)
+	cmd.On("Run", "docker compose --project-name my-app up")
+
+	ch, err := c.UpWithResult(nil, nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := <-ch
+
+	cmd.AssertExpectations(t)
+	cmd.AssertNotCalled(t, "SetStdout", mock.Anything)
+
+	if result.Service("web") == nil {
+		t.Errorf("expected a result for web, got %+v", result.Services)
+	}
+}
+
+func TestUpWithResultRunError(t *testing.T) {
+	cmd := &mockUpResultCmd{}
+
+	c := &client.ComposeClient{
+		GlobalOptions: &client.GlobalOptions{
+			ProjectName: runErrFlag,
+		},
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	cmd.On("SetStderr", mock.Anything)
+	cmd.On("Run", mock.Anything)
+
+	_, err := c.UpWithResult(nil, nil)
+
+	if err == nil || err.Error() != runErrFlag {
+		t.Errorf("expected error %s, got %v", runErrFlag, err)
+	}
+}
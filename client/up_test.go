@@ -1,9 +1,11 @@
 package client_test
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/harrim91/docker-compose-go/client"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestUpCommand(t *testing.T) {
@@ -351,3 +353,282 @@ func TestUpServices(t *testing.T) {
 
 	cmd.AssertExpectations(t)
 }
+
+func TestUpAbortOnContainerFailure(t *testing.T) {
+	cmd := &MockCmd{}
+
+	c := &client.ComposeClient{
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	cmd.On("Run", "docker compose up --abort-on-container-failure")
+
+	c.Up(&client.UpOptions{
+		AbortOnContainerFailure: true,
+	}, nil)
+
+	cmd.AssertExpectations(t)
+}
+
+func TestUpAttach(t *testing.T) {
+	cmd := &MockCmd{}
+
+	c := &client.ComposeClient{
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	cmd.On("Run", "docker compose up --attach foo --attach bar")
+
+	c.Up(&client.UpOptions{
+		Attach: []string{"foo", "bar"},
+	}, nil)
+
+	cmd.AssertExpectations(t)
+}
+
+func TestUpNoAttach(t *testing.T) {
+	cmd := &MockCmd{}
+
+	c := &client.ComposeClient{
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	cmd.On("Run", "docker compose up --no-attach foo --no-attach bar")
+
+	c.Up(&client.UpOptions{
+		NoAttach: []string{"foo", "bar"},
+	}, nil)
+
+	cmd.AssertExpectations(t)
+}
+
+func TestUpNoLogPrefix(t *testing.T) {
+	cmd := &MockCmd{}
+
+	c := &client.ComposeClient{
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	cmd.On("Run", "docker compose up --no-log-prefix")
+
+	c.Up(&client.UpOptions{
+		NoLogPrefix: true,
+	}, nil)
+
+	cmd.AssertExpectations(t)
+}
+
+func TestUpTimestamps(t *testing.T) {
+	cmd := &MockCmd{}
+
+	c := &client.ComposeClient{
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	cmd.On("Run", "docker compose up --timestamps")
+
+	c.Up(&client.UpOptions{
+		Timestamps: true,
+	}, nil)
+
+	cmd.AssertExpectations(t)
+}
+
+func TestUpPullAlways(t *testing.T) {
+	cmd := &MockCmd{}
+
+	c := &client.ComposeClient{
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	cmd.On("Run", "docker compose up --pull always")
+
+	c.Up(&client.UpOptions{
+		Pull: client.PullPolicyFlagAlways,
+	}, nil)
+
+	cmd.AssertExpectations(t)
+}
+
+func TestUpPullMissing(t *testing.T) {
+	cmd := &MockCmd{}
+
+	c := &client.ComposeClient{
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	cmd.On("Run", "docker compose up --pull missing")
+
+	c.Up(&client.UpOptions{
+		Pull: client.PullPolicyFlagMissing,
+	}, nil)
+
+	cmd.AssertExpectations(t)
+}
+
+func TestUpPullNever(t *testing.T) {
+	cmd := &MockCmd{}
+
+	c := &client.ComposeClient{
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	cmd.On("Run", "docker compose up --pull never")
+
+	c.Up(&client.UpOptions{
+		Pull: client.PullPolicyFlagNever,
+	}, nil)
+
+	cmd.AssertExpectations(t)
+}
+
+func TestUpPullBuild(t *testing.T) {
+	cmd := &MockCmd{}
+
+	c := &client.ComposeClient{
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	cmd.On("Run", "docker compose up --pull build")
+
+	c.Up(&client.UpOptions{
+		Pull: client.PullPolicyFlagBuild,
+	}, nil)
+
+	cmd.AssertExpectations(t)
+}
+
+func TestUpWait(t *testing.T) {
+	cmd := &MockCmd{}
+
+	c := &client.ComposeClient{
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	cmd.On("Run", "docker compose up --wait")
+
+	c.Up(&client.UpOptions{
+		Wait: true,
+	}, nil)
+
+	cmd.AssertExpectations(t)
+}
+
+func TestUpWaitTimeout(t *testing.T) {
+	cmd := &MockCmd{}
+
+	c := &client.ComposeClient{
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	waitTimeout := 30
+
+	cmd.On("Run", "docker compose up --wait-timeout 30")
+
+	c.Up(&client.UpOptions{
+		WaitTimeout: &waitTimeout,
+	}, nil)
+
+	cmd.AssertExpectations(t)
+}
+
+func TestUpMenu(t *testing.T) {
+	cmd := &MockCmd{}
+
+	c := &client.ComposeClient{
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	menu := false
+
+	cmd.On("Run", "docker compose up --menu=false")
+
+	c.Up(&client.UpOptions{
+		Menu: &menu,
+	}, nil)
+
+	cmd.AssertExpectations(t)
+}
+
+func TestUpWatch(t *testing.T) {
+	cmd := &MockCmd{}
+
+	c := &client.ComposeClient{
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	cmd.On("Run", "docker compose up --watch")
+
+	c.Up(&client.UpOptions{
+		Watch: true,
+	}, nil)
+
+	cmd.AssertExpectations(t)
+}
+
+func TestUpYes(t *testing.T) {
+	cmd := &MockCmd{}
+
+	c := &client.ComposeClient{
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	cmd.On("Run", "docker compose up --yes")
+
+	c.Up(&client.UpOptions{
+		Yes: true,
+	}, nil)
+
+	cmd.AssertExpectations(t)
+}
+
+func TestUpUnsupportedFlag(t *testing.T) {
+	cmd := &MockCmd{}
+
+	c := &client.ComposeClient{
+		ComposeVersion: "v2.0.0",
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	_, err := c.Up(&client.UpOptions{
+		Wait: true,
+	}, nil)
+
+	var unsupported *client.UnsupportedFlagError
+
+	if !errors.As(err, &unsupported) || unsupported.Flag != "--wait" {
+		t.Errorf("expected UnsupportedFlagError for --wait, got %v", err)
+	}
+
+	cmd.AssertNotCalled(t, "Run", mock.Anything)
+}
@@ -0,0 +1,27 @@
+package client
+
+import "sort"
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sortedKeys returns the keys of m, sorted. The compose package has its own copy, as it doesn't depend on this one and
+// the helper isn't worth exporting.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
@@ -21,6 +21,10 @@ type mockVersionCmd struct {
 	stderr io.Writer
 }
 
+func (o *mockVersionCmd) SetStdin(stdin io.Reader) {
+	o.Called(stdin)
+}
+
 func (o *mockVersionCmd) SetStdout(stdout io.Writer) {
 	o.Called(stdout)
 	o.stdout = stdout
@@ -75,3 +79,28 @@ func TestVersion(t *testing.T) {
 		t.Errorf("expected: %s, got: %s", version, res)
 	}
 }
+
+func TestDetectVersion(t *testing.T) {
+	cmd := &mockVersionCmd{}
+
+	c := &client.ComposeClient{
+		NewCmd: func() client.Cmd {
+			return cmd
+		},
+	}
+
+	cmd.On("SetStdout", mock.Anything)
+	cmd.On("Run", "docker compose version --format json")
+
+	_, err := c.DetectVersion()
+
+	if err != nil {
+		t.Error(err)
+	}
+
+	cmd.AssertExpectations(t)
+
+	if c.ComposeVersion != version {
+		t.Errorf("expected: %s, got: %s", version, c.ComposeVersion)
+	}
+}
@@ -1,15 +1,27 @@
 package cmd
 
 import (
+	"errors"
 	"io"
+	"os"
 	"os/exec"
+	"sync"
 )
 
+// ErrNotRunning is returned when signalling a Cmd that isn't running
+var ErrNotRunning = errors.New("command is not running")
+
 type executor func(name string, arg ...string) *exec.Cmd
 
 // Cmd is used for executing shell commands
+//
+// On Linux, the shell is started in its own process group, unless stdin is a terminal. Signals are sent to the whole
+// group, so they reach docker compose rather than only the shell, and any processes left in the group are killed once
+// the shell exits.
 type Cmd struct {
+	proc
 	Exec   executor
+	stdin  io.Reader
 	stdout io.Writer
 	stderr io.Writer
 }
@@ -21,6 +33,13 @@ func New() *Cmd {
 	}
 }
 
+// Sets the stdin reader.
+//
+// If stdin is not an *os.File, the command doesn't complete until the reader returns EOF or an error.
+func (c *Cmd) SetStdin(stdin io.Reader) {
+	c.stdin = stdin
+}
+
 // Sets the stdout writer
 func (c *Cmd) SetStderr(stderr io.Writer) {
 	c.stderr = stderr
@@ -35,6 +54,10 @@ func (c *Cmd) SetStdout(stdout io.Writer) {
 func (c *Cmd) Run(command string) (<-chan error, error) {
 	execcmd := c.Exec("/bin/sh", "-c", command)
 
+	if c.stdin != nil {
+		execcmd.Stdin = c.stdin
+	}
+
 	if c.stdout != nil {
 		execcmd.Stdout = c.stdout
 	}
@@ -43,16 +66,90 @@ func (c *Cmd) Run(command string) (<-chan error, error) {
 		execcmd.Stderr = c.stderr
 	}
 
+	group := setProcessGroup(execcmd)
+
 	if err := execcmd.Start(); err != nil {
 		return nil, err
 	}
 
+	c.started(execcmd.Process, group)
+
 	ch := make(chan error)
 
 	go func() {
 		defer close(ch)
-		ch <- execcmd.Wait()
+
+		err := waitProcessGroup(execcmd, group)
+
+		c.exited(execcmd.ProcessState)
+
+		ch <- err
 	}()
 
 	return ch, nil
 }
+
+// proc tracks the process started by a Cmd or PTY
+type proc struct {
+	mu      sync.Mutex
+	process *os.Process
+	pid     int
+	state   *os.ProcessState
+
+	// Whether the process leads its own process group
+	group bool
+}
+
+func (p *proc) started(process *os.Process, group bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.process = process
+	p.pid = process.Pid
+	p.group = group
+}
+
+func (p *proc) exited(state *os.ProcessState) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.process = nil
+	p.state = state
+}
+
+// Signal sends a signal to the running command and, on Linux, every process in its group. Returns ErrNotRunning if the command hasn't started or has completed.
+func (p *proc) Signal(sig os.Signal) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.process == nil {
+		return ErrNotRunning
+	}
+
+	if !p.group {
+		return p.process.Signal(sig)
+	}
+
+	return signalProcessGroup(p.process, sig)
+}
+
+// Kill immediately stops the running command and, on Linux, every process in its group. Returns ErrNotRunning if the command hasn't started or has completed.
+func (p *proc) Kill() error {
+	return p.Signal(os.Kill)
+}
+
+// Pid returns the process ID of the shell running the command, or 0 if the command hasn't started
+func (p *proc) Pid() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.pid
+}
+
+// ProcessState returns the state of the shell once the command has completed, or nil if it is still running
+func (p *proc) ProcessState() *os.ProcessState {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.state
+}
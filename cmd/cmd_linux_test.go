@@ -0,0 +1,265 @@
+//go:build linux
+
+package cmd_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/harrim91/docker-compose-go/cmd"
+)
+
+// spawnCmd returns a Cmd whose shell is substituted by TestShellProcessSpawn
+func spawnCmd(wait bool) *cmd.Cmd {
+	return &cmd.Cmd{
+		Exec: func(command string, args ...string) *exec.Cmd {
+			cs := []string{"-test.run=TestShellProcessSpawn", "--", command}
+			cs = append(cs, args...)
+			cmd := exec.Command(os.Args[0], cs...)
+			cmd.Env = []string{"GO_TEST_PROCESS=1", fmt.Sprintf("GO_TEST_SPAWN_WAIT=%t", wait)}
+			return cmd
+		},
+	}
+}
+
+// waitForPid reads the grandchild pid written to stdout by TestShellProcessSpawn
+func waitForPid(t *testing.T, buff *safeBuffer) int {
+	deadline := time.Now().Add(5 * time.Second)
+
+	for time.Now().Before(deadline) {
+		if s := buff.String(); strings.HasSuffix(s, "\n") {
+			pid, err := strconv.Atoi(strings.TrimSpace(s))
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			return pid
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatal("timed out waiting for the grandchild pid")
+
+	return 0
+}
+
+// waitForExit waits for the process to no longer exist
+func waitForExit(t *testing.T, pid int) {
+	deadline := time.Now().Add(5 * time.Second)
+
+	for time.Now().Before(deadline) {
+		if err := syscall.Kill(pid, 0); errors.Is(err, syscall.ESRCH) {
+			return
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	syscall.Kill(pid, syscall.SIGKILL)
+	t.Errorf("expected process %d to have been killed", pid)
+}
+
+func TestCommandSignalProcessGroup(t *testing.T) {
+	c := spawnCmd(true)
+
+	var buff safeBuffer
+
+	c.SetStdout(&buff)
+
+	ch, err := c.Run("docker compose up")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pid := waitForPid(t, &buff)
+
+	if err := c.Signal(syscall.SIGTERM); err != nil {
+		t.Fatal(err)
+	}
+
+	<-ch
+
+	waitForExit(t, pid)
+}
+
+// openTerminal opens a new pseudo-terminal, returning its terminal end
+func openTerminal(t *testing.T) *os.File {
+	ptmx, err := os.OpenFile("/dev/ptmx", os.O_RDWR, 0)
+
+	if err != nil {
+		t.Skipf("pseudo-terminals aren't available: %v", err)
+	}
+
+	t.Cleanup(func() { ptmx.Close() })
+
+	var unlock int32
+	var n uint32
+
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, ptmx.Fd(), syscall.TIOCSPTLCK, uintptr(unsafe.Pointer(&unlock))); errno != 0 {
+		t.Fatal(errno)
+	}
+
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, ptmx.Fd(), syscall.TIOCGPTN, uintptr(unsafe.Pointer(&n))); errno != 0 {
+		t.Fatal(errno)
+	}
+
+	tty, err := os.OpenFile(fmt.Sprintf("/dev/pts/%d", n), os.O_RDWR|syscall.O_NOCTTY, 0)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { tty.Close() })
+
+	return tty
+}
+
+// shellProcessGroup runs a shell with the given stdin, returning its pid and process group ID
+func shellProcessGroup(t *testing.T, stdin *os.File) (int, int) {
+	c := cmd.New()
+
+	var buff safeBuffer
+
+	c.SetStdout(&buff)
+
+	if stdin != nil {
+		c.SetStdin(stdin)
+	}
+
+	// The process group is the fifth field of /proc/<pid>/stat
+	ch, err := c.Run("echo $$ $(cut -d ' ' -f 5 /proc/$$/stat)")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := <-ch; err != nil {
+		t.Fatal(err)
+	}
+
+	var pid, pgid int
+
+	if _, err := fmt.Sscan(buff.String(), &pid, &pgid); err != nil {
+		t.Fatalf("unexpected output %q: %v", buff.String(), err)
+	}
+
+	return pid, pgid
+}
+
+func TestCommandProcessGroup(t *testing.T) {
+	if pid, pgid := shellProcessGroup(t, nil); pgid != pid {
+		t.Errorf("expected the shell to lead its own process group, got %d for pid %d", pgid, pid)
+	}
+}
+
+func TestCommandTerminalStdinProcessGroup(t *testing.T) {
+	if _, pgid := shellProcessGroup(t, openTerminal(t)); pgid != syscall.Getpgrp() {
+		t.Errorf("expected the shell to stay in the caller's process group %d, got %d", syscall.Getpgrp(), pgid)
+	}
+}
+
+func TestCommandKill(t *testing.T) {
+	c := spawnCmd(true)
+
+	var buff safeBuffer
+
+	c.SetStdout(&buff)
+
+	ch, err := c.Run("docker compose up")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pid := waitForPid(t, &buff)
+
+	if err := c.Kill(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "signal: killed"
+
+	if err := <-ch; err == nil || err.Error() != expected {
+		t.Errorf("expected error '%s', got: '%v'", expected, err)
+	}
+
+	waitForExit(t, pid)
+}
+
+func TestCommandKillsOrphans(t *testing.T) {
+	c := spawnCmd(false)
+
+	var buff safeBuffer
+
+	c.SetStdout(&buff)
+
+	ch, err := c.Run("docker compose up")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := <-ch; err != nil {
+		t.Fatal(err)
+	}
+
+	waitForExit(t, waitForPid(t, &buff))
+}
+
+// TestShellProcessSpawn is a method that is called as a substitute for a shell command that starts a child process.
+// It starts TestShellProcessSleep as a child, writes its pid to STDOUT, then waits to be killed if GO_TEST_SPAWN_WAIT is true, or exits.
+// The GO_TEST_PROCESS flag ensures that if it is called as part of the test suite, it is skipped.
+func TestShellProcessSpawn(t *testing.T) {
+	if os.Getenv("GO_TEST_PROCESS") != "1" {
+		return
+	}
+
+	child := exec.Command(os.Args[0], "-test.run=TestShellProcessSleep")
+	child.Env = []string{"GO_TEST_PROCESS=1"}
+
+	if err := child.Start(); err != nil {
+		fmt.Fprint(os.Stderr, err)
+		os.Exit(errExitCode)
+	}
+
+	fmt.Fprintf(os.Stdout, "%d\n", child.Process.Pid)
+
+	if os.Getenv("GO_TEST_SPAWN_WAIT") == "true" {
+		child.Wait()
+	}
+
+	os.Exit(successExitCode)
+}
+
+// safeBuffer is a bytes.Buffer that can be written by the command while the test reads it
+type safeBuffer struct {
+	buff bytes.Buffer
+	mu   sync.Mutex
+}
+
+func (b *safeBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buff.Write(p)
+}
+
+func (b *safeBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.buff.String()
+}
@@ -2,10 +2,15 @@ package cmd_test
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
+	"strings"
+	"syscall"
 	"testing"
+	"time"
 
 	"github.com/harrim91/docker-compose-go/cmd"
 )
@@ -13,6 +18,7 @@ import (
 const (
 	stdoutMessage = "stdout message"
 	stderrMessage = "stderr message"
+	stdinMessage  = "stdin message"
 )
 
 const (
@@ -182,6 +188,126 @@ func TestCommandStderr(t *testing.T) {
 	}
 }
 
+func TestCommandStdin(t *testing.T) {
+	cmd := cmd.Cmd{
+		Exec: func(command string, args ...string) *exec.Cmd {
+			cs := []string{"-test.run=TestShellProcessEcho", "--", command}
+			cs = append(cs, args...)
+			cmd := exec.Command(os.Args[0], cs...)
+			cmd.Env = []string{"GO_TEST_PROCESS=1"}
+			return cmd
+		},
+	}
+
+	var buff bytes.Buffer
+
+	cmd.SetStdin(strings.NewReader(stdinMessage))
+	cmd.SetStdout(&buff)
+
+	ch, err := cmd.Run("cat")
+
+	if err != nil {
+		t.Errorf("expected no error from cmd.Run, got: %v", err)
+		return
+	}
+
+	if err := <-ch; err != nil {
+		t.Errorf("expected no error from channel, got: %v", err)
+		return
+	}
+
+	got := buff.String()
+	want := stdinMessage
+
+	if got != want {
+		t.Errorf("want: %v, got: %v", want, got)
+	}
+}
+
+func TestCommandSignal(t *testing.T) {
+	c := cmd.Cmd{
+		Exec: func(command string, args ...string) *exec.Cmd {
+			cs := []string{"-test.run=TestShellProcessSleep", "--", command}
+			cs = append(cs, args...)
+			cmd := exec.Command(os.Args[0], cs...)
+			cmd.Env = []string{"GO_TEST_PROCESS=1"}
+			return cmd
+		},
+	}
+
+	ch, err := c.Run("sleep 10")
+
+	if err != nil {
+		t.Errorf("expected no error from cmd.Run, got: %v", err)
+		return
+	}
+
+	if err := c.Signal(syscall.SIGTERM); err != nil {
+		t.Errorf("expected no error from cmd.Signal, got: %v", err)
+		return
+	}
+
+	select {
+	case err = <-ch:
+	case <-time.After(5 * time.Second):
+		t.Error("expected the command to exit after being signalled")
+		return
+	}
+
+	expected := "signal: terminated"
+
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected error '%s', got: '%v'", expected, err)
+	}
+
+	if err := c.Signal(syscall.SIGTERM); !errors.Is(err, cmd.ErrNotRunning) {
+		t.Errorf("expected ErrNotRunning once the command has exited, got: %v", err)
+	}
+}
+
+func TestCommandProcessState(t *testing.T) {
+	c := cmd.Cmd{
+		Exec: func(command string, args ...string) *exec.Cmd {
+			cs := []string{"-test.run=TestShellProcessError", "--", command}
+			cs = append(cs, args...)
+			cmd := exec.Command(os.Args[0], cs...)
+			cmd.Env = []string{"GO_TEST_PROCESS=1"}
+			return cmd
+		},
+	}
+
+	if c.Pid() != 0 || c.ProcessState() != nil {
+		t.Errorf("expected no pid or state before the command has started")
+	}
+
+	ch, err := c.Run("echo hello")
+
+	if err != nil {
+		t.Errorf("expected no error from cmd.Run, got: %v", err)
+		return
+	}
+
+	if c.Pid() == 0 {
+		t.Error("expected a pid once the command has started")
+	}
+
+	<-ch
+
+	state := c.ProcessState()
+
+	if state == nil {
+		t.Fatal("expected a state once the command has completed")
+	}
+
+	if state.ExitCode() != errExitCode {
+		t.Errorf("expected exit code %d, got: %d", errExitCode, state.ExitCode())
+	}
+
+	if c.Pid() != state.Pid() {
+		t.Errorf("expected pid %d, got: %d", state.Pid(), c.Pid())
+	}
+}
+
 // TestShellProcessSuccess is a method that is called as a substitute for a shell command.
 // It writes a predetermined message to STDOUT and returns an exit code of 0
 // The GO_TEST_PROCESS flag ensures that if it is called as part of the test suite, it is skipped.
@@ -207,3 +333,32 @@ func TestShellProcessError(t *testing.T) {
 
 	os.Exit(errExitCode)
 }
+
+// TestShellProcessEcho is a method that is called as a substitute for a shell command.
+// It copies STDIN to STDOUT and returns an exit code of 0
+// The GO_TEST_PROCESS flag ensures that if it is called as part of the test suite, it is skipped.
+func TestShellProcessEcho(t *testing.T) {
+	if os.Getenv("GO_TEST_PROCESS") != "1" {
+		return
+	}
+
+	if _, err := io.Copy(os.Stdout, os.Stdin); err != nil {
+		fmt.Fprint(os.Stderr, err)
+		os.Exit(errExitCode)
+	}
+
+	os.Exit(successExitCode)
+}
+
+// TestShellProcessSleep is a method that is called as a substitute for a long running shell command.
+// It sleeps until it is killed by a signal, or for 10 seconds.
+// The GO_TEST_PROCESS flag ensures that if it is called as part of the test suite, it is skipped.
+func TestShellProcessSleep(t *testing.T) {
+	if os.Getenv("GO_TEST_PROCESS") != "1" {
+		return
+	}
+
+	time.Sleep(10 * time.Second)
+
+	os.Exit(successExitCode)
+}
@@ -0,0 +1,82 @@
+//go:build linux
+
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+	"unsafe"
+)
+
+// From <sys/wait.h>, which the syscall package doesn't define
+const (
+	pPID    = 1
+	wNOWAIT = 0x1000000
+)
+
+// setProcessGroup starts the command in a new process group, so that docker compose and any processes it starts can be
+// signalled together. Returns whether it did.
+//
+// A command whose stdin is a terminal stays in the caller's process group, as a background group is stopped with
+// SIGTTIN when it reads from the terminal.
+func setProcessGroup(execcmd *exec.Cmd) bool {
+	if f, ok := execcmd.Stdin.(*os.File); ok && isTerminal(f) {
+		return false
+	}
+
+	if execcmd.SysProcAttr == nil {
+		execcmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+
+	execcmd.SysProcAttr.Setpgid = true
+
+	return true
+}
+
+func isTerminal(f *os.File) bool {
+	var termios syscall.Termios
+
+	return ioctl(f, syscall.TCGETS, unsafe.Pointer(&termios)) == nil
+}
+
+// signalProcessGroup sends a signal to every process in the process's group
+func signalProcessGroup(process *os.Process, sig os.Signal) error {
+	s, ok := sig.(syscall.Signal)
+
+	if !ok {
+		return process.Signal(sig)
+	}
+
+	return syscall.Kill(-process.Pid, s)
+}
+
+// waitProcessGroup waits for the command to complete. If it leads its own process group, any processes left in the
+// group are killed first, while the exited shell is yet to be reaped, so the group ID can't have been reused.
+func waitProcessGroup(execcmd *exec.Cmd, group bool) error {
+	if group && waitExited(execcmd.Process.Pid) == nil {
+		syscall.Kill(-execcmd.Process.Pid, syscall.SIGKILL)
+	}
+
+	return execcmd.Wait()
+}
+
+// waitExited blocks until the process has exited, leaving it to be reaped by Wait
+func waitExited(pid int) error {
+	// Large enough for a siginfo_t
+	var info [128]byte
+
+	for {
+		_, _, errno := syscall.Syscall6(syscall.SYS_WAITID, pPID, uintptr(pid), uintptr(unsafe.Pointer(&info[0])), syscall.WEXITED|wNOWAIT, 0, 0)
+
+		if errno == syscall.EINTR {
+			continue
+		}
+
+		if errno != 0 {
+			return errno
+		}
+
+		return nil
+	}
+}
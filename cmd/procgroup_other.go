@@ -0,0 +1,23 @@
+//go:build !linux
+
+package cmd
+
+import (
+	"os"
+	"os/exec"
+)
+
+// setProcessGroup is a no-op on platforms without process group support
+func setProcessGroup(execcmd *exec.Cmd) bool {
+	return false
+}
+
+// signalProcessGroup sends a signal to the process only, on platforms without process group support
+func signalProcessGroup(process *os.Process, sig os.Signal) error {
+	return process.Signal(sig)
+}
+
+// waitProcessGroup waits for the command to complete
+func waitProcessGroup(execcmd *exec.Cmd, group bool) error {
+	return execcmd.Wait()
+}
@@ -0,0 +1,222 @@
+package cmd
+
+import (
+	"errors"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sync"
+)
+
+// ErrPTYUnsupported is returned when running a PTY on a platform without pseudo-terminal support
+var ErrPTYUnsupported = errors.New("pseudo-terminals are not supported on this platform")
+
+const (
+	defaultRows = 24
+	defaultCols = 80
+)
+
+// PTY is used for executing shell commands attached to a pseudo-terminal, so docker compose renders the same
+// interactive progress output and colours it does when run directly in a terminal.
+//
+// It can be used in place of Cmd, e.g. by setting ComposeClient.NewCmd. Pseudo-terminals are only supported on Linux.
+// On other platforms, Run returns ErrPTYUnsupported.
+//
+// The terminal combines stdout and stderr. Its output is written to the stdout writer, or to the stderr writer if no stdout writer is set.
+type PTY struct {
+	proc
+	Exec executor
+
+	// The terminal whose window size is copied to the pseudo-terminal and kept in sync as it is resized, e.g. os.Stdout. Optional.
+	Terminal *os.File
+
+	// The window size of the pseudo-terminal when there is no Terminal (default: 24 rows, 80 columns)
+	Rows uint16
+	Cols uint16
+
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	ptmxMu sync.Mutex
+	ptmx   *os.File
+}
+
+// NewPTY returns a new PTY that follows the window size of the given terminal, which may be nil
+func NewPTY(terminal *os.File) *PTY {
+	return &PTY{
+		Exec:     exec.Command,
+		Terminal: terminal,
+	}
+}
+
+// Sets the stdin reader. Input is written to the pseudo-terminal as if it were typed.
+func (c *PTY) SetStdin(stdin io.Reader) {
+	c.stdin = stdin
+}
+
+// Sets the stdout writer
+func (c *PTY) SetStdout(stdout io.Writer) {
+	c.stdout = stdout
+}
+
+// Sets the stderr writer. It is only used if no stdout writer is set.
+func (c *PTY) SetStderr(stderr io.Writer) {
+	c.stderr = stderr
+}
+
+// Run a shell command attached to a new pseudo-terminal. The returned channel will emit a single message and then close once the command has completed.
+func (c *PTY) Run(command string) (<-chan error, error) {
+	ptmx, tty, err := openPTY()
+
+	if err != nil {
+		return nil, err
+	}
+
+	rows, cols := c.size()
+
+	if err := setWindowSize(ptmx, rows, cols); err != nil {
+		ptmx.Close()
+		tty.Close()
+		return nil, err
+	}
+
+	execcmd := c.Exec("/bin/sh", "-c", command)
+	execcmd.Stdin = tty
+	execcmd.Stdout = tty
+	execcmd.Stderr = tty
+
+	setControllingTerminal(execcmd)
+
+	err = execcmd.Start()
+
+	// The child has its own copy of the terminal, and keeping ours open would stop reads from ptmx ending once it exits
+	tty.Close()
+
+	if err != nil {
+		ptmx.Close()
+		return nil, err
+	}
+
+	// The session leader also leads a new process group
+	c.started(execcmd.Process, true)
+
+	c.ptmxMu.Lock()
+	c.ptmx = ptmx
+	c.ptmxMu.Unlock()
+
+	output := make(chan struct{})
+
+	go func() {
+		defer close(output)
+
+		// Reading from ptmx fails with EIO once every process attached to the terminal has exited
+		io.Copy(c.output(), ptmx)
+	}()
+
+	if c.stdin != nil {
+		go io.Copy(ptmx, c.stdin)
+	}
+
+	stopResize := c.forwardResize(ptmx)
+
+	ch := make(chan error)
+
+	go func() {
+		defer close(ch)
+
+		err := waitProcessGroup(execcmd, true)
+
+		c.exited(execcmd.ProcessState)
+
+		<-output
+
+		stopResize()
+
+		c.ptmxMu.Lock()
+		c.ptmx = nil
+		c.ptmxMu.Unlock()
+
+		ptmx.Close()
+
+		ch <- err
+	}()
+
+	return ch, nil
+}
+
+// Resize sets the window size of the pseudo-terminal. Returns ErrNotRunning if the command hasn't started or has completed.
+func (c *PTY) Resize(rows, cols uint16) error {
+	c.ptmxMu.Lock()
+	defer c.ptmxMu.Unlock()
+
+	if c.ptmx == nil {
+		return ErrNotRunning
+	}
+
+	return setWindowSize(c.ptmx, rows, cols)
+}
+
+// output returns the writer that the terminal's output is copied to
+func (c *PTY) output() io.Writer {
+	if c.stdout != nil {
+		return c.stdout
+	}
+
+	if c.stderr != nil {
+		return c.stderr
+	}
+
+	return io.Discard
+}
+
+// size returns the window size of the Terminal if there is one, or the configured size otherwise
+func (c *PTY) size() (uint16, uint16) {
+	if c.Terminal != nil {
+		if rows, cols, err := getWindowSize(c.Terminal); err == nil {
+			return rows, cols
+		}
+	}
+
+	rows, cols := c.Rows, c.Cols
+
+	if rows == 0 {
+		rows = defaultRows
+	}
+
+	if cols == 0 {
+		cols = defaultCols
+	}
+
+	return rows, cols
+}
+
+// forwardResize copies the Terminal's window size to ptmx whenever it changes, until the returned function is called
+func (c *PTY) forwardResize(ptmx *os.File) func() {
+	if c.Terminal == nil {
+		return func() {}
+	}
+
+	signals := make(chan os.Signal, 1)
+	stop := make(chan struct{})
+
+	notifyResize(signals)
+
+	go func() {
+		for {
+			select {
+			case <-signals:
+				if rows, cols, err := getWindowSize(c.Terminal); err == nil {
+					setWindowSize(ptmx, rows, cols)
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(signals)
+		close(stop)
+	}
+}
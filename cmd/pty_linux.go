@@ -0,0 +1,112 @@
+//go:build linux
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+	"unsafe"
+)
+
+// winsize mirrors struct winsize from <sys/ioctl.h>
+type winsize struct {
+	Rows   uint16
+	Cols   uint16
+	XPixel uint16
+	YPixel uint16
+}
+
+// ioctl performs an ioctl on the file without switching it to blocking mode
+func ioctl(f *os.File, req uintptr, arg unsafe.Pointer) error {
+	conn, err := f.SyscallConn()
+
+	if err != nil {
+		return err
+	}
+
+	var errno syscall.Errno
+
+	err = conn.Control(func(fd uintptr) {
+		_, _, errno = syscall.Syscall(syscall.SYS_IOCTL, fd, req, uintptr(arg))
+	})
+
+	if err != nil {
+		return err
+	}
+
+	if errno != 0 {
+		return errno
+	}
+
+	return nil
+}
+
+// openPTY allocates a new pseudo-terminal, returning its master and slave ends
+func openPTY() (*os.File, *os.File, error) {
+	ptmx, err := os.OpenFile("/dev/ptmx", os.O_RDWR|syscall.O_NOCTTY, 0)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var unlock int32
+
+	if err := ioctl(ptmx, syscall.TIOCSPTLCK, unsafe.Pointer(&unlock)); err != nil {
+		ptmx.Close()
+		return nil, nil, fmt.Errorf("unlocking pseudo-terminal: %w", err)
+	}
+
+	var n uint32
+
+	if err := ioctl(ptmx, syscall.TIOCGPTN, unsafe.Pointer(&n)); err != nil {
+		ptmx.Close()
+		return nil, nil, fmt.Errorf("getting pseudo-terminal number: %w", err)
+	}
+
+	tty, err := os.OpenFile(fmt.Sprintf("/dev/pts/%d", n), os.O_RDWR|syscall.O_NOCTTY, 0)
+
+	if err != nil {
+		ptmx.Close()
+		return nil, nil, err
+	}
+
+	return ptmx, tty, nil
+}
+
+// getWindowSize returns the window size of the terminal
+func getWindowSize(f *os.File) (uint16, uint16, error) {
+	var ws winsize
+
+	if err := ioctl(f, syscall.TIOCGWINSZ, unsafe.Pointer(&ws)); err != nil {
+		return 0, 0, err
+	}
+
+	return ws.Rows, ws.Cols, nil
+}
+
+// setWindowSize sets the window size of the terminal, which sends SIGWINCH to its foreground processes
+func setWindowSize(f *os.File, rows, cols uint16) error {
+	ws := winsize{Rows: rows, Cols: cols}
+
+	return ioctl(f, syscall.TIOCSWINSZ, unsafe.Pointer(&ws))
+}
+
+// setControllingTerminal starts the command in a new session, with its stdin as the controlling terminal.
+// The session leader also leads a new process group, so the command can be signalled in the same way as a Cmd.
+func setControllingTerminal(execcmd *exec.Cmd) {
+	if execcmd.SysProcAttr == nil {
+		execcmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+
+	execcmd.SysProcAttr.Setsid = true
+	execcmd.SysProcAttr.Setctty = true
+	execcmd.SysProcAttr.Ctty = 0
+}
+
+// notifyResize relays window size changes of the controlling terminal to ch
+func notifyResize(ch chan<- os.Signal) {
+	signal.Notify(ch, syscall.SIGWINCH)
+}
@@ -0,0 +1,219 @@
+//go:build linux
+
+package cmd_test
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/harrim91/docker-compose-go/cmd"
+)
+
+// ptyCmd returns a PTY whose shell is substituted by the given helper process
+func ptyCmd(helper string) *cmd.PTY {
+	return &cmd.PTY{
+		Exec: func(command string, args ...string) *exec.Cmd {
+			cs := []string{"-test.run=" + helper, "--", command}
+			cs = append(cs, args...)
+			cmd := exec.Command(os.Args[0], cs...)
+			cmd.Env = []string{"GO_TEST_PROCESS=1"}
+			return cmd
+		},
+	}
+}
+
+// waitForOutput waits for the buffer to contain s
+func waitForOutput(t *testing.T, buff *safeBuffer, s string) {
+	deadline := time.Now().Add(5 * time.Second)
+
+	for time.Now().Before(deadline) {
+		if strings.Contains(buff.String(), s) {
+			return
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	t.Fatalf("timed out waiting for %q, got: %q", s, buff.String())
+}
+
+func TestPTYRun(t *testing.T) {
+	c := ptyCmd("TestShellProcessTerminal")
+	c.Rows = 30
+	c.Cols = 100
+
+	var buff safeBuffer
+
+	c.SetStderr(&buff)
+
+	ch, err := c.Run("docker compose up")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := <-ch; err != nil {
+		t.Errorf("expected no error from channel, got: %v", err)
+	}
+
+	expected := "30 100\r\n"
+
+	if got := buff.String(); got != expected {
+		t.Errorf("want: %q, got: %q", expected, got)
+	}
+
+	if err := c.Resize(40, 120); err != cmd.ErrNotRunning {
+		t.Errorf("expected ErrNotRunning once the command has exited, got: %v", err)
+	}
+}
+
+func TestPTYResize(t *testing.T) {
+	c := ptyCmd("TestShellProcessResize")
+
+	var buff safeBuffer
+
+	c.SetStdout(&buff)
+
+	ch, err := c.Run("docker compose up")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	waitForOutput(t, &buff, "24 80\r\n")
+
+	if err := c.Resize(40, 120); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := <-ch; err != nil {
+		t.Errorf("expected no error from channel, got: %v", err)
+	}
+
+	waitForOutput(t, &buff, "40 120\r\n")
+}
+
+func TestPTYStdin(t *testing.T) {
+	c := ptyCmd("TestShellProcessEcho")
+
+	var buff safeBuffer
+
+	// Ctrl-D at the start of a line ends the input
+	c.SetStdin(strings.NewReader(stdinMessage + "\n\x04"))
+	c.SetStdout(&buff)
+
+	ch, err := c.Run("cat")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := <-ch; err != nil {
+		t.Errorf("expected no error from channel, got: %v", err)
+	}
+
+	if got := buff.String(); !strings.Contains(got, stdinMessage) {
+		t.Errorf("expected output to contain %q, got: %q", stdinMessage, got)
+	}
+}
+
+func TestPTYSignal(t *testing.T) {
+	c := ptyCmd("TestShellProcessSleep")
+
+	ch, err := c.Run("sleep 10")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Signal(syscall.SIGTERM); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "signal: terminated"
+
+	select {
+	case err = <-ch:
+		if err == nil || err.Error() != expected {
+			t.Errorf("expected error '%s', got: '%v'", expected, err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Error("expected the command to exit after being signalled")
+	}
+}
+
+// terminalSize returns the window size of the terminal attached to STDOUT
+func terminalSize() (uint16, uint16, error) {
+	var ws struct {
+		Rows, Cols, XPixel, YPixel uint16
+	}
+
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, os.Stdout.Fd(), syscall.TIOCGWINSZ, uintptr(unsafe.Pointer(&ws)))
+
+	if errno != 0 {
+		return 0, 0, errno
+	}
+
+	return ws.Rows, ws.Cols, nil
+}
+
+// TestShellProcessTerminal is a method that is called as a substitute for a shell command that expects a terminal.
+// It writes the window size of the terminal to STDOUT and returns an exit code of 0, or 1 if STDOUT isn't a terminal.
+// The GO_TEST_PROCESS flag ensures that if it is called as part of the test suite, it is skipped.
+func TestShellProcessTerminal(t *testing.T) {
+	if os.Getenv("GO_TEST_PROCESS") != "1" {
+		return
+	}
+
+	rows, cols, err := terminalSize()
+
+	if err != nil {
+		fmt.Fprint(os.Stderr, err)
+		os.Exit(errExitCode)
+	}
+
+	fmt.Fprintf(os.Stdout, "%d %d\n", rows, cols)
+
+	os.Exit(successExitCode)
+}
+
+// TestShellProcessResize is a method that is called as a substitute for a shell command that redraws when its terminal is resized.
+// It writes the window size of the terminal to STDOUT, and again once it receives SIGWINCH.
+// The GO_TEST_PROCESS flag ensures that if it is called as part of the test suite, it is skipped.
+func TestShellProcessResize(t *testing.T) {
+	if os.Getenv("GO_TEST_PROCESS") != "1" {
+		return
+	}
+
+	resized := make(chan os.Signal, 1)
+
+	signal.Notify(resized, syscall.SIGWINCH)
+
+	for i := 0; i < 2; i++ {
+		rows, cols, err := terminalSize()
+
+		if err != nil {
+			fmt.Fprint(os.Stderr, err)
+			os.Exit(errExitCode)
+		}
+
+		fmt.Fprintf(os.Stdout, "%d %d\n", rows, cols)
+
+		if i == 0 {
+			select {
+			case <-resized:
+			case <-time.After(5 * time.Second):
+				os.Exit(errExitCode)
+			}
+		}
+	}
+
+	os.Exit(successExitCode)
+}
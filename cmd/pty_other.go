@@ -0,0 +1,29 @@
+//go:build !linux
+
+package cmd
+
+import (
+	"os"
+	"os/exec"
+)
+
+// openPTY always fails on platforms without pseudo-terminal support
+func openPTY() (*os.File, *os.File, error) {
+	return nil, nil, ErrPTYUnsupported
+}
+
+// getWindowSize always fails on platforms without pseudo-terminal support
+func getWindowSize(f *os.File) (uint16, uint16, error) {
+	return 0, 0, ErrPTYUnsupported
+}
+
+// setWindowSize always fails on platforms without pseudo-terminal support
+func setWindowSize(f *os.File, rows, cols uint16) error {
+	return ErrPTYUnsupported
+}
+
+// setControllingTerminal is a no-op on platforms without pseudo-terminal support
+func setControllingTerminal(execcmd *exec.Cmd) {}
+
+// notifyResize is a no-op on platforms without pseudo-terminal support
+func notifyResize(ch chan<- os.Signal) {}
@@ -0,0 +1,268 @@
+package compose
+
+import "fmt"
+
+// Builder builds a Project in code. It should be created with `NewBuilder`.
+//
+//	b := compose.NewBuilder("preview")
+//	b.Volume("db-data", nil)
+//	b.Service("db").Image("postgres:16").Volume("db-data", "/var/lib/postgresql/data").
+//		Healthcheck(&compose.Healthcheck{Test: []string{"CMD", "pg_isready"}})
+//	b.Service("web").Image("my/web:1.2").Publish(8080, 80).DependsOn("db", compose.ConditionServiceHealthy)
+//	project, err := b.Build()
+type Builder struct {
+	project  *Project
+	services map[string]*ServiceBuilder
+}
+
+// NewBuilder returns a Builder for a project with the given name
+func NewBuilder(name string) *Builder {
+	return &Builder{
+		project: &Project{
+			Name:     name,
+			Services: map[string]*Service{},
+		},
+		services: map[string]*ServiceBuilder{},
+	}
+}
+
+// Service returns the builder for the named service, adding the service if it hasn't been already
+func (b *Builder) Service(name string) *ServiceBuilder {
+	if s, ok := b.services[name]; ok {
+		return s
+	}
+
+	s := &ServiceBuilder{service: &Service{}}
+
+	b.project.Services[name] = s.service
+	b.services[name] = s
+
+	return s
+}
+
+// Network defines a network. The network may be nil to use the default settings.
+func (b *Builder) Network(name string, network *Network) *Builder {
+	if b.project.Networks == nil {
+		b.project.Networks = map[string]*Network{}
+	}
+
+	b.project.Networks[name] = network
+
+	return b
+}
+
+// Volume defines a named volume. The volume may be nil to use the default settings.
+func (b *Builder) Volume(name string, volume *Volume) *Builder {
+	if b.project.Volumes == nil {
+		b.project.Volumes = map[string]*Volume{}
+	}
+
+	b.project.Volumes[name] = volume
+
+	return b
+}
+
+// Secret defines a secret
+func (b *Builder) Secret(name string, secret *Secret) *Builder {
+	if b.project.Secrets == nil {
+		b.project.Secrets = map[string]*Secret{}
+	}
+
+	b.project.Secrets[name] = secret
+
+	return b
+}
+
+// Build validates and returns the project. Returns a *ValidationError if the project refers to anything that isn't defined.
+//
+// The project is shared with the Builder, so further changes to the Builder are reflected in it.
+func (b *Builder) Build() (*Project, error) {
+	if err := b.project.Validate(); err != nil {
+		return nil, err
+	}
+
+	return b.project, nil
+}
+
+// YAML validates the project, and returns it as a Compose file
+func (b *Builder) YAML() ([]byte, error) {
+	p, err := b.Build()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return p.YAML()
+}
+
+// ServiceBuilder configures a service. It should be created with `Builder.Service`.
+type ServiceBuilder struct {
+	service *Service
+}
+
+// Image sets the image the service runs
+func (s *ServiceBuilder) Image(image string) *ServiceBuilder {
+	s.service.Image = image
+	return s
+}
+
+// Build sets how the service's image is built
+func (s *ServiceBuilder) Build(build *Build) *ServiceBuilder {
+	s.service.Build = build
+	return s
+}
+
+// Command overrides the image's default command
+func (s *ServiceBuilder) Command(args ...string) *ServiceBuilder {
+	s.service.Command = args
+	return s
+}
+
+// Env sets an environment variable
+func (s *ServiceBuilder) Env(key, value string) *ServiceBuilder {
+	if s.service.Environment == nil {
+		s.service.Environment = map[string]*string{}
+	}
+
+	s.service.Environment[key] = &value
+
+	return s
+}
+
+// Label sets a container label
+func (s *ServiceBuilder) Label(key, value string) *ServiceBuilder {
+	if s.service.Labels == nil {
+		s.service.Labels = map[string]string{}
+	}
+
+	s.service.Labels[key] = value
+
+	return s
+}
+
+// Port adds a port
+func (s *ServiceBuilder) Port(port Port) *ServiceBuilder {
+	s.service.Ports = append(s.service.Ports, port)
+	return s
+}
+
+// Publish publishes the container's target port on the given host port
+func (s *ServiceBuilder) Publish(published, target uint32) *ServiceBuilder {
+	return s.Port(Port{
+		Target:    target,
+		Published: fmt.Sprint(published),
+	})
+}
+
+// Mount adds a mount
+func (s *ServiceBuilder) Mount(volume ServiceVolume) *ServiceBuilder {
+	s.service.Volumes = append(s.service.Volumes, volume)
+	return s
+}
+
+// Volume mounts the named volume, which must be defined with `Builder.Volume`, at the target path
+func (s *ServiceBuilder) Volume(name, target string) *ServiceBuilder {
+	return s.Mount(ServiceVolume{
+		Type:   VolumeTypeVolume,
+		Source: name,
+		Target: target,
+	})
+}
+
+// Bind mounts the host path at the target path
+func (s *ServiceBuilder) Bind(source, target string) *ServiceBuilder {
+	return s.Mount(ServiceVolume{
+		Type:   VolumeTypeBind,
+		Source: source,
+		Target: target,
+	})
+}
+
+// Tmpfs mounts a tmpfs at the target path
+func (s *ServiceBuilder) Tmpfs(target string) *ServiceBuilder {
+	return s.Mount(ServiceVolume{
+		Type:   VolumeTypeTmpfs,
+		Target: target,
+	})
+}
+
+// VolumesFrom mounts all the volumes of another service
+func (s *ServiceBuilder) VolumesFrom(service string) *ServiceBuilder {
+	s.service.VolumesFrom = append(s.service.VolumesFrom, service)
+	return s
+}
+
+// Network attaches the service to a network, which must be defined with `Builder.Network` unless it is `default`
+func (s *ServiceBuilder) Network(name string, aliases ...string) *ServiceBuilder {
+	if s.service.Networks == nil {
+		s.service.Networks = map[string]*ServiceNetwork{}
+	}
+
+	var network *ServiceNetwork
+
+	if len(aliases) > 0 {
+		network = &ServiceNetwork{Aliases: aliases}
+	}
+
+	s.service.Networks[name] = network
+
+	return s
+}
+
+// NetworkMode sets the network mode, e.g. `host` or `service:name`
+func (s *ServiceBuilder) NetworkMode(mode string) *ServiceBuilder {
+	s.service.NetworkMode = mode
+	return s
+}
+
+// Link links to another service
+func (s *ServiceBuilder) Link(service string) *ServiceBuilder {
+	s.service.Links = append(s.service.Links, service)
+	return s
+}
+
+// DependsOn starts the service once the other service meets the condition (e.g. ConditionServiceHealthy).
+// An empty condition waits for the service to start.
+func (s *ServiceBuilder) DependsOn(service, condition string) *ServiceBuilder {
+	if s.service.DependsOn == nil {
+		s.service.DependsOn = map[string]*Dependency{}
+	}
+
+	if condition == "" {
+		condition = ConditionServiceStarted
+	}
+
+	s.service.DependsOn[service] = &Dependency{Condition: condition}
+
+	return s
+}
+
+// Healthcheck sets the service's healthcheck
+func (s *ServiceBuilder) Healthcheck(healthcheck *Healthcheck) *ServiceBuilder {
+	s.service.Healthcheck = healthcheck
+	return s
+}
+
+// Secret grants the service access to a secret, which must be defined with `Builder.Secret`
+func (s *ServiceBuilder) Secret(name string) *ServiceBuilder {
+	s.service.Secrets = append(s.service.Secrets, ServiceSecret{Source: name})
+	return s
+}
+
+// Profiles sets the profiles the service is enabled by
+func (s *ServiceBuilder) Profiles(profiles ...string) *ServiceBuilder {
+	s.service.Profiles = profiles
+	return s
+}
+
+// Restart sets the restart policy, e.g. `always` or `on-failure`
+func (s *ServiceBuilder) Restart(policy string) *ServiceBuilder {
+	s.service.Restart = policy
+	return s
+}
+
+// Apply calls fn with the service, to set anything the ServiceBuilder doesn't have a method for
+func (s *ServiceBuilder) Apply(fn func(service *Service)) *ServiceBuilder {
+	fn(s.service)
+	return s
+}
@@ -0,0 +1,156 @@
+package compose_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/harrim91/docker-compose-go/compose"
+)
+
+const builtYAML = `name: preview
+services:
+  db:
+    image: postgres:16
+    environment:
+      POSTGRES_PASSWORD_FILE: /run/secrets/db_password
+    volumes:
+      - type: volume
+        source: db-data
+        target: /var/lib/postgresql/data
+    networks:
+      backend: null
+    healthcheck:
+      test:
+        - CMD
+        - pg_isready
+      interval: 5s
+    secrets:
+      - source: db_password
+  web:
+    image: my/web:1.2
+    ports:
+      - target: 80
+        published: "8080"
+    volumes:
+      - type: tmpfs
+        target: /tmp
+    networks:
+      backend:
+        aliases:
+          - app
+      default: null
+    depends_on:
+      db:
+        condition: service_healthy
+networks:
+  backend: null
+volumes:
+  db-data: null
+secrets:
+  db_password:
+    file: ./secrets/db_password
+`
+
+func TestBuilder(t *testing.T) {
+	b := compose.NewBuilder("preview")
+
+	b.Network("backend", nil).
+		Volume("db-data", nil).
+		Secret("db_password", &compose.Secret{File: "./secrets/db_password"})
+
+	b.Service("web").
+		Image("my/web:1.2").
+		Publish(8080, 80).
+		Tmpfs("/tmp").
+		Network("default").
+		Network("backend", "app").
+		DependsOn("db", compose.ConditionServiceHealthy)
+
+	b.Service("db").
+		Image("postgres:16").
+		Env("POSTGRES_PASSWORD_FILE", "/run/secrets/db_password").
+		Volume("db-data", "/var/lib/postgresql/data").
+		Network("backend").
+		Secret("db_password").
+		Healthcheck(&compose.Healthcheck{
+			Test:     []string{"CMD", "pg_isready"},
+			Interval: "5s",
+		})
+
+	b2 := b.Service("web")
+
+	if b2 != b.Service("web") {
+		t.Error("expected the same builder for the same service")
+	}
+
+	out, err := b.YAML()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(out) != builtYAML {
+		t.Errorf("expected:\n%s\ngot:\n%s", builtYAML, out)
+	}
+}
+
+func TestBuilderValidation(t *testing.T) {
+	b := compose.NewBuilder("preview")
+
+	b.Service("web").
+		Image("my/web:1.2").
+		DependsOn("db", compose.ConditionServiceHealthy).
+		DependsOn("web", "").
+		Link("cache").
+		VolumesFrom("data:ro").
+		Volume("uploads", "/uploads").
+		Network("backend").
+		Secret("api_key").
+		Port(compose.Port{Protocol: "sctp"})
+
+	b.Service("worker").
+		NetworkMode("service:vpn")
+
+	_, err := b.Build()
+
+	var validationErr *compose.ValidationError
+
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected a ValidationError, got %v", err)
+	}
+
+	expected := []string{
+		`services.web.depends_on.db: service "db" is not defined`,
+		`services.web.depends_on.web: service depends on itself`,
+		`services.web.links: service "cache" is not defined`,
+		`services.web.volumes_from: service "data" is not defined`,
+		`services.web.networks: network "backend" is not defined`,
+		`services.web.ports[0]: target port is required`,
+		`services.web.ports[0]: unknown protocol "sctp"`,
+		`services.web.volumes[0]: volume "uploads" is not defined`,
+		`services.web.secrets[0]: secret "api_key" is not defined`,
+		`services.worker: service has neither an image nor a build`,
+		`services.worker.network_mode: service "vpn" is not defined`,
+	}
+
+	if !reflect.DeepEqual(validationErr.Problems, expected) {
+		t.Errorf("expected:\n%v\ngot:\n%v", expected, validationErr.Problems)
+	}
+}
+
+func TestBuilderHealthcheckDisabled(t *testing.T) {
+	b := compose.NewBuilder("preview")
+
+	b.Service("db").Image("postgres:16").Healthcheck(&compose.Healthcheck{Disable: true})
+	b.Service("web").Image("my/web:1.2").DependsOn("db", compose.ConditionServiceHealthy)
+	b.Service("worker").Image("my/worker:1.2").DependsOn("web", compose.ConditionServiceHealthy)
+
+	_, err := b.Build()
+
+	expected := `services.web.depends_on.db: service "db" has its healthcheck disabled`
+
+	if err == nil || err.Error() != expected {
+		t.Errorf("expected %s, got %v", expected, err)
+	}
+}
@@ -0,0 +1,332 @@
+// Package compose models the Compose file format, as output by `docker compose config --format json`.
+//
+// The model covers the parts of the Compose specification used by this library. Fields outside of it are ignored when parsing.
+//
+// https://docs.docker.com/compose/compose-file/
+package compose
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Conditions for DependsOn
+const (
+	ConditionServiceStarted               = "service_started"
+	ConditionServiceHealthy               = "service_healthy"
+	ConditionServiceCompletedSuccessfully = "service_completed_successfully"
+)
+
+// Types of ServiceVolume
+const (
+	VolumeTypeBind   = "bind"
+	VolumeTypeVolume = "volume"
+	VolumeTypeTmpfs  = "tmpfs"
+)
+
+// Project is a Compose application: its services and the networks, volumes and secrets they use
+type Project struct {
+	// The project name
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+
+	Services map[string]*Service `json:"services" yaml:"services"`
+
+	Networks map[string]*Network `json:"networks,omitempty" yaml:"networks,omitempty"`
+
+	Volumes map[string]*Volume `json:"volumes,omitempty" yaml:"volumes,omitempty"`
+
+	Secrets map[string]*Secret `json:"secrets,omitempty" yaml:"secrets,omitempty"`
+}
+
+// Service is the definition of a service
+//
+// https://docs.docker.com/compose/compose-file/05-services/
+type Service struct {
+	Image string `json:"image,omitempty" yaml:"image,omitempty"`
+
+	Build *Build `json:"build,omitempty" yaml:"build,omitempty"`
+
+	ContainerName string `json:"container_name,omitempty" yaml:"container_name,omitempty"`
+
+	Command []string `json:"command,omitempty" yaml:"command,omitempty"`
+
+	Entrypoint []string `json:"entrypoint,omitempty" yaml:"entrypoint,omitempty"`
+
+	// Environment variables. A nil value passes the variable through from the environment compose runs in.
+	Environment map[string]*string `json:"environment,omitempty" yaml:"environment,omitempty"`
+
+	Ports []Port `json:"ports,omitempty" yaml:"ports,omitempty"`
+
+	Volumes []ServiceVolume `json:"volumes,omitempty" yaml:"volumes,omitempty"`
+
+	// Services (or `container:name`) to mount all volumes from
+	VolumesFrom []string `json:"volumes_from,omitempty" yaml:"volumes_from,omitempty"`
+
+	// Networks to attach to, keyed by network name. A nil value attaches with the default settings.
+	Networks map[string]*ServiceNetwork `json:"networks,omitempty" yaml:"networks,omitempty"`
+
+	// e.g. `host`, `none`, `service:name` or `container:name`. Can't be used with Networks.
+	NetworkMode string `json:"network_mode,omitempty" yaml:"network_mode,omitempty"`
+
+	// Links to services, as `service` or `service:alias`
+	Links []string `json:"links,omitempty" yaml:"links,omitempty"`
+
+	// Services to start before this one, keyed by service name
+	DependsOn map[string]*Dependency `json:"depends_on,omitempty" yaml:"depends_on,omitempty"`
+
+	Healthcheck *Healthcheck `json:"healthcheck,omitempty" yaml:"healthcheck,omitempty"`
+
+	Secrets []ServiceSecret `json:"secrets,omitempty" yaml:"secrets,omitempty"`
+
+	Privileged bool `json:"privileged,omitempty" yaml:"privileged,omitempty"`
+
+	// e.g. `no`, `always`, `on-failure`, `unless-stopped`
+	Restart string `json:"restart,omitempty" yaml:"restart,omitempty"`
+
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+
+	Profiles []string `json:"profiles,omitempty" yaml:"profiles,omitempty"`
+
+	Deploy *Deploy `json:"deploy,omitempty" yaml:"deploy,omitempty"`
+
+	// Short forms of the deploy resource limits, e.g. `0.5` and `512m`
+	CPUs     string `json:"cpus,omitempty" yaml:"cpus,omitempty"`
+	MemLimit string `json:"mem_limit,omitempty" yaml:"mem_limit,omitempty"`
+}
+
+// Build configures how a service's image is built
+type Build struct {
+	Context string `json:"context,omitempty" yaml:"context,omitempty"`
+
+	Dockerfile string `json:"dockerfile,omitempty" yaml:"dockerfile,omitempty"`
+
+	Args map[string]*string `json:"args,omitempty" yaml:"args,omitempty"`
+
+	Target string `json:"target,omitempty" yaml:"target,omitempty"`
+}
+
+// Port is a container port, optionally published on the host
+type Port struct {
+	// The container port
+	Target uint32 `json:"target" yaml:"target"`
+
+	// The host port or range (e.g. `8080`, `8000-8010`). Empty means the port isn't published, or a random port for `ingress` mode.
+	Published string `json:"published,omitempty" yaml:"published,omitempty"`
+
+	HostIP string `json:"host_ip,omitempty" yaml:"host_ip,omitempty"`
+
+	// `tcp` or `udp` (default: tcp)
+	Protocol string `json:"protocol,omitempty" yaml:"protocol,omitempty"`
+
+	// `host` or `ingress`
+	Mode string `json:"mode,omitempty" yaml:"mode,omitempty"`
+}
+
+// ServiceVolume is a mount in a service's containers
+type ServiceVolume struct {
+	// `bind`, `volume`, `tmpfs`, `image`, `npipe` or `cluster`
+	Type string `json:"type" yaml:"type"`
+
+	// The host path for `bind`, the volume name for `volume`, or the image for `image`. Empty for `tmpfs` and anonymous volumes.
+	Source string `json:"source,omitempty" yaml:"source,omitempty"`
+
+	// The path in the container
+	Target string `json:"target" yaml:"target"`
+
+	ReadOnly bool `json:"read_only,omitempty" yaml:"read_only,omitempty"`
+
+	// `consistent`, `cached` or `delegated`. Only used by Docker Desktop for Mac.
+	Consistency string `json:"consistency,omitempty" yaml:"consistency,omitempty"`
+
+	Bind *BindOptions `json:"bind,omitempty" yaml:"bind,omitempty"`
+
+	Volume *VolumeOptions `json:"volume,omitempty" yaml:"volume,omitempty"`
+
+	Tmpfs *TmpfsOptions `json:"tmpfs,omitempty" yaml:"tmpfs,omitempty"`
+
+	Image *ImageOptions `json:"image,omitempty" yaml:"image,omitempty"`
+}
+
+type BindOptions struct {
+	// e.g. `rprivate`, `rshared` or `slave`
+	Propagation string `json:"propagation,omitempty" yaml:"propagation,omitempty"`
+
+	CreateHostPath bool `json:"create_host_path,omitempty" yaml:"create_host_path,omitempty"`
+
+	// `z` to share the SELinux label with other containers, or `Z` to make it private
+	SELinux string `json:"selinux,omitempty" yaml:"selinux,omitempty"`
+
+	// `enabled`, `disabled`, `writable` or `readonly`
+	Recursive string `json:"recursive,omitempty" yaml:"recursive,omitempty"`
+}
+
+type VolumeOptions struct {
+	NoCopy bool `json:"nocopy,omitempty" yaml:"nocopy,omitempty"`
+
+	// The path within the volume to mount
+	Subpath string `json:"subpath,omitempty" yaml:"subpath,omitempty"`
+}
+
+type TmpfsOptions struct {
+	// Size in bytes, or with a unit (e.g. `64m`)
+	Size string `json:"size,omitempty" yaml:"size,omitempty"`
+
+	// The file mode of the mount, e.g. 0o1777
+	Mode uint32 `json:"mode,omitempty" yaml:"mode,omitempty"`
+}
+
+type ImageOptions struct {
+	// The path within the image to mount
+	Subpath string `json:"subpath,omitempty" yaml:"subpath,omitempty"`
+}
+
+// ServiceNetwork configures how a service attaches to a network
+type ServiceNetwork struct {
+	Aliases []string `json:"aliases,omitempty" yaml:"aliases,omitempty"`
+
+	IPv4Address string `json:"ipv4_address,omitempty" yaml:"ipv4_address,omitempty"`
+
+	IPv6Address string `json:"ipv6_address,omitempty" yaml:"ipv6_address,omitempty"`
+}
+
+// Dependency configures how a service depends on another
+type Dependency struct {
+	// `service_started`, `service_healthy` or `service_completed_successfully` (default: service_started)
+	Condition string `json:"condition,omitempty" yaml:"condition,omitempty"`
+
+	// Restart this service when the dependency is updated
+	Restart bool `json:"restart,omitempty" yaml:"restart,omitempty"`
+
+	// Whether the dependency must be running or enabled (default: true)
+	Required *bool `json:"required,omitempty" yaml:"required,omitempty"`
+}
+
+// Healthcheck configures how a service's containers are checked for health. Durations are strings like `30s`.
+type Healthcheck struct {
+	// e.g. `["CMD", "curl", "-f", "http://localhost"]` or `["CMD-SHELL", "curl -f http://localhost"]`
+	Test []string `json:"test,omitempty" yaml:"test,omitempty"`
+
+	Interval string `json:"interval,omitempty" yaml:"interval,omitempty"`
+
+	Timeout string `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+
+	Retries *uint64 `json:"retries,omitempty" yaml:"retries,omitempty"`
+
+	StartPeriod string `json:"start_period,omitempty" yaml:"start_period,omitempty"`
+
+	Disable bool `json:"disable,omitempty" yaml:"disable,omitempty"`
+}
+
+// ServiceSecret grants a service access to a secret
+type ServiceSecret struct {
+	// The name of the secret in the project's Secrets
+	Source string `json:"source" yaml:"source"`
+
+	// The file name or path in the container (default: /run/secrets/<source>)
+	Target string `json:"target,omitempty" yaml:"target,omitempty"`
+}
+
+// Deploy configures the deployment of a service
+type Deploy struct {
+	Replicas *int `json:"replicas,omitempty" yaml:"replicas,omitempty"`
+
+	Resources *Resources `json:"resources,omitempty" yaml:"resources,omitempty"`
+}
+
+type Resources struct {
+	Limits *Resource `json:"limits,omitempty" yaml:"limits,omitempty"`
+
+	Reservations *Resource `json:"reservations,omitempty" yaml:"reservations,omitempty"`
+}
+
+type Resource struct {
+	// Number of CPUs (e.g. `0.5`)
+	CPUs string `json:"cpus,omitempty" yaml:"cpus,omitempty"`
+
+	// Memory in bytes, or with a unit (e.g. `512m`)
+	Memory string `json:"memory,omitempty" yaml:"memory,omitempty"`
+}
+
+// Network is a network defined by the project
+type Network struct {
+	// The name of the network in Docker (default: <project>_<key>)
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+
+	Driver string `json:"driver,omitempty" yaml:"driver,omitempty"`
+
+	// The network is managed outside of the project
+	External bool `json:"external,omitempty" yaml:"external,omitempty"`
+
+	Internal bool `json:"internal,omitempty" yaml:"internal,omitempty"`
+
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+}
+
+// Volume is a named volume defined by the project
+type Volume struct {
+	// The name of the volume in Docker (default: <project>_<key>)
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+
+	Driver string `json:"driver,omitempty" yaml:"driver,omitempty"`
+
+	DriverOpts map[string]string `json:"driver_opts,omitempty" yaml:"driver_opts,omitempty"`
+
+	// The volume is managed outside of the project
+	External bool `json:"external,omitempty" yaml:"external,omitempty"`
+
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+}
+
+// Secret is a secret defined by the project
+type Secret struct {
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+
+	// Path to a file containing the secret
+	File string `json:"file,omitempty" yaml:"file,omitempty"`
+
+	// Environment variable containing the secret
+	Environment string `json:"environment,omitempty" yaml:"environment,omitempty"`
+
+	// The secret is managed outside of the project
+	External bool `json:"external,omitempty" yaml:"external,omitempty"`
+}
+
+// ParseJSON parses the output of `docker compose config --format json`
+func ParseJSON(b []byte) (*Project, error) {
+	p := &Project{}
+
+	if err := json.Unmarshal(b, p); err != nil {
+		return nil, err
+	}
+
+	if p.Services == nil {
+		p.Services = map[string]*Service{}
+	}
+
+	return p, nil
+}
+
+// YAML returns the project as a Compose file, with keys sorted and 2 space indentation
+func (p *Project) YAML() ([]byte, error) {
+	var buff bytes.Buffer
+
+	enc := yaml.NewEncoder(&buff)
+	enc.SetIndent(2)
+
+	if err := enc.Encode(p); err != nil {
+		return nil, err
+	}
+
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+
+	return buff.Bytes(), nil
+}
+
+// ServiceNames returns the names of the project's services, sorted
+func (p *Project) ServiceNames() []string {
+	return sortedKeys(p.Services)
+}
@@ -0,0 +1,111 @@
+package compose_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/harrim91/docker-compose-go/compose"
+)
+
+const configJSON = `{
+  "name": "my-app",
+  "services": {
+    "web": {
+      "image": "nginx:1.25",
+      "command": ["nginx", "-g", "daemon off;"],
+      "environment": {"MODE": "prod", "TOKEN": null},
+      "ports": [
+        {"mode": "ingress", "target": 80, "published": "8080", "protocol": "tcp"},
+        {"mode": "ingress", "target": 443, "published": 8443, "protocol": "tcp"}
+      ],
+      "volumes": [
+        {"type": "tmpfs", "target": "/cache", "tmpfs": {"size": 67108864, "mode": "0o1777"}},
+        {"type": "volume", "source": "data", "target": "/data", "volume": {}}
+      ],
+      "networks": {"default": null},
+      "depends_on": {"db": {"condition": "service_healthy", "required": true, "restart": false}},
+      "deploy": {"resources": {"limits": {"cpus": 0.5, "memory": "536870912"}}},
+      "cpus": 0.25,
+      "mem_limit": 268435456,
+      "unknown_field": {"ignored": true}
+    },
+    "db": {
+      "image": "postgres:16",
+      "healthcheck": {"test": ["CMD", "pg_isready"], "interval": "5s", "retries": 3}
+    }
+  },
+  "networks": {"default": {"name": "my-app_default", "ipam": {}, "external": false}},
+  "volumes": {"data": {"name": "shared-data", "external": {"name": "shared-data"}}}
+}`
+
+func TestParseJSON(t *testing.T) {
+	p, err := compose.ParseJSON([]byte(configJSON))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if p.Name != "my-app" || !reflect.DeepEqual(p.ServiceNames(), []string{"db", "web"}) {
+		t.Errorf("unexpected project: %+v", p)
+	}
+
+	web := p.Services["web"]
+
+	if web.Environment["MODE"] == nil || *web.Environment["MODE"] != "prod" || web.Environment["TOKEN"] != nil {
+		t.Errorf("unexpected environment: %v", web.Environment)
+	}
+
+	if web.Ports[0].Published != "8080" || web.Ports[1].Published != "8443" || web.Ports[1].Target != 443 {
+		t.Errorf("unexpected ports: %+v", web.Ports)
+	}
+
+	if web.Volumes[0].Tmpfs.Size != "67108864" || web.Volumes[0].Tmpfs.Mode != 0o1777 || web.Volumes[1].Source != "data" {
+		t.Errorf("unexpected volumes: %+v", web.Volumes)
+	}
+
+	if dependency := web.DependsOn["db"]; dependency.Condition != compose.ConditionServiceHealthy || !*dependency.Required {
+		t.Errorf("unexpected dependency: %+v", dependency)
+	}
+
+	if limits := web.Deploy.Resources.Limits; limits.CPUs != "0.5" || limits.Memory != "536870912" {
+		t.Errorf("unexpected limits: %+v", limits)
+	}
+
+	if web.CPUs != "0.25" || web.MemLimit != "268435456" {
+		t.Errorf("unexpected short limits: %s %s", web.CPUs, web.MemLimit)
+	}
+
+	if db := p.Services["db"]; *db.Healthcheck.Retries != 3 || db.Healthcheck.Interval != "5s" {
+		t.Errorf("unexpected healthcheck: %+v", db.Healthcheck)
+	}
+
+	if p.Networks["default"].External || !p.Volumes["data"].External {
+		t.Errorf("unexpected external resources: %+v %+v", p.Networks["default"], p.Volumes["data"])
+	}
+
+	if err := p.Validate(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestParseJSONEmpty(t *testing.T) {
+	p, err := compose.ParseJSON([]byte(`{}`))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if p.Services == nil {
+		t.Error("expected an empty services map")
+	}
+
+	out, err := p.YAML()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(out) != "services: {}\n" {
+		t.Errorf("unexpected YAML: %q", out)
+	}
+}
@@ -0,0 +1,200 @@
+package compose
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strconv"
+)
+
+// flexString unmarshals a JSON string or number. Depending on the version, compose outputs sizes and published ports as either.
+type flexString string
+
+func (s *flexString) UnmarshalJSON(b []byte) error {
+	var str string
+
+	if err := json.Unmarshal(b, &str); err == nil {
+		*s = flexString(str)
+		return nil
+	}
+
+	var n json.Number
+
+	if err := json.Unmarshal(b, &n); err != nil {
+		return err
+	}
+
+	*s = flexString(n.String())
+
+	return nil
+}
+
+// flexBool unmarshals a JSON bool, or an object meaning true. Older versions of compose output `external: {name: ...}`.
+type flexBool bool
+
+func (f *flexBool) UnmarshalJSON(b []byte) error {
+	if bytes.HasPrefix(bytes.TrimSpace(b), []byte("{")) {
+		*f = true
+		return nil
+	}
+
+	var v bool
+
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+
+	*f = flexBool(v)
+
+	return nil
+}
+
+func (p *Port) UnmarshalJSON(b []byte) error {
+	type port Port
+
+	aux := struct {
+		*port
+		Published flexString `json:"published"`
+	}{port: (*port)(p)}
+
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+
+	p.Published = string(aux.Published)
+
+	return nil
+}
+
+func (s *Service) UnmarshalJSON(b []byte) error {
+	type service Service
+
+	aux := struct {
+		*service
+		CPUs     flexString `json:"cpus"`
+		MemLimit flexString `json:"mem_limit"`
+	}{service: (*service)(s)}
+
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+
+	s.CPUs = string(aux.CPUs)
+	s.MemLimit = string(aux.MemLimit)
+
+	return nil
+}
+
+func (t *TmpfsOptions) UnmarshalJSON(b []byte) error {
+	type tmpfs TmpfsOptions
+
+	aux := struct {
+		*tmpfs
+		Size flexString `json:"size"`
+
+		// A number, or an octal string like `0o1777` in newer versions of compose
+		Mode flexString `json:"mode"`
+	}{tmpfs: (*tmpfs)(t)}
+
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+
+	t.Size = string(aux.Size)
+
+	if aux.Mode != "" {
+		mode, err := strconv.ParseUint(string(aux.Mode), 0, 32)
+
+		if err != nil {
+			return err
+		}
+
+		t.Mode = uint32(mode)
+	}
+
+	return nil
+}
+
+func (r *Resource) UnmarshalJSON(b []byte) error {
+	type resource Resource
+
+	aux := struct {
+		*resource
+		CPUs   flexString `json:"cpus"`
+		Memory flexString `json:"memory"`
+	}{resource: (*resource)(r)}
+
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+
+	r.CPUs = string(aux.CPUs)
+	r.Memory = string(aux.Memory)
+
+	return nil
+}
+
+func (n *Network) UnmarshalJSON(b []byte) error {
+	type network Network
+
+	aux := struct {
+		*network
+		External flexBool `json:"external"`
+	}{network: (*network)(n)}
+
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+
+	n.External = bool(aux.External)
+
+	return nil
+}
+
+func (v *Volume) UnmarshalJSON(b []byte) error {
+	type volume Volume
+
+	aux := struct {
+		*volume
+		External flexBool `json:"external"`
+	}{volume: (*volume)(v)}
+
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+
+	v.External = bool(aux.External)
+
+	return nil
+}
+
+func (s *Secret) UnmarshalJSON(b []byte) error {
+	type secret Secret
+
+	aux := struct {
+		*secret
+		External flexBool `json:"external"`
+	}{secret: (*secret)(s)}
+
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+
+	s.External = bool(aux.External)
+
+	return nil
+}
+
+// sortedKeys returns the keys of m, sorted. The client package has its own copy, as this package doesn't depend on
+// it and the helper isn't worth exporting.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,166 @@
+package compose
+
+import (
+	"fmt"
+	"strings"
+)
+
+// defaultNetwork is created for every project, so can be used without being defined
+const defaultNetwork = "default"
+
+// ValidationError lists the problems found in a Project, e.g. `services.web.depends_on.db: service "db" is not defined`
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return strings.Join(e.Problems, "; ")
+}
+
+// Validate checks that every service has an image or build, and that the services, networks, volumes and secrets each service refers to are defined.
+//
+// Returns a *ValidationError listing every problem found, or nil.
+func (p *Project) Validate() error {
+	v := &validator{project: p}
+
+	for _, name := range p.ServiceNames() {
+		v.service(name, p.Services[name])
+	}
+
+	if len(v.problems) > 0 {
+		return &ValidationError{Problems: v.problems}
+	}
+
+	return nil
+}
+
+type validator struct {
+	project  *Project
+	problems []string
+}
+
+func (v *validator) problem(path, format string, args ...interface{}) {
+	v.problems = append(v.problems, fmt.Sprintf("%s: %s", path, fmt.Sprintf(format, args...)))
+}
+
+// serviceDefined reports a problem if the named service isn't defined
+func (v *validator) serviceDefined(path, name string) {
+	if _, ok := v.project.Services[name]; !ok {
+		v.problem(path, "service %q is not defined", name)
+	}
+}
+
+func (v *validator) service(name string, s *Service) {
+	path := fmt.Sprintf("services.%s", name)
+
+	if s == nil {
+		v.problem(path, "service is empty")
+		return
+	}
+
+	if s.Image == "" && s.Build == nil {
+		v.problem(path, "service has neither an image nor a build")
+	}
+
+	for _, dependency := range sortedKeys(s.DependsOn) {
+		v.dependency(name, dependency, s.DependsOn[dependency])
+	}
+
+	for _, link := range s.Links {
+		v.serviceDefined(path+".links", strings.SplitN(link, ":", 2)[0])
+	}
+
+	for _, from := range s.VolumesFrom {
+		if !strings.HasPrefix(from, "container:") {
+			v.serviceDefined(path+".volumes_from", strings.SplitN(strings.TrimPrefix(from, "service:"), ":", 2)[0])
+		}
+	}
+
+	if strings.HasPrefix(s.NetworkMode, "service:") {
+		v.serviceDefined(path+".network_mode", strings.TrimPrefix(s.NetworkMode, "service:"))
+	}
+
+	if s.NetworkMode != "" && len(s.Networks) > 0 {
+		v.problem(path, "network_mode and networks can't be used together")
+	}
+
+	for _, network := range sortedKeys(s.Networks) {
+		if _, ok := v.project.Networks[network]; !ok && network != defaultNetwork {
+			v.problem(path+".networks", "network %q is not defined", network)
+		}
+	}
+
+	for i, port := range s.Ports {
+		if port.Target == 0 {
+			v.problem(fmt.Sprintf("%s.ports[%d]", path, i), "target port is required")
+		}
+
+		if port.Protocol != "" && port.Protocol != "tcp" && port.Protocol != "udp" {
+			v.problem(fmt.Sprintf("%s.ports[%d]", path, i), "unknown protocol %q", port.Protocol)
+		}
+	}
+
+	for i, volume := range s.Volumes {
+		v.volume(fmt.Sprintf("%s.volumes[%d]", path, i), volume)
+	}
+
+	for i, secret := range s.Secrets {
+		if _, ok := v.project.Secrets[secret.Source]; !ok {
+			v.problem(fmt.Sprintf("%s.secrets[%d]", path, i), "secret %q is not defined", secret.Source)
+		}
+	}
+}
+
+func (v *validator) dependency(name, dependency string, d *Dependency) {
+	path := fmt.Sprintf("services.%s.depends_on.%s", name, dependency)
+
+	if dependency == name {
+		v.problem(path, "service depends on itself")
+		return
+	}
+
+	target, ok := v.project.Services[dependency]
+
+	if !ok {
+		v.problem(path, "service %q is not defined", dependency)
+		return
+	}
+
+	if d == nil {
+		return
+	}
+
+	switch d.Condition {
+	case "", ConditionServiceStarted, ConditionServiceCompletedSuccessfully:
+	case ConditionServiceHealthy:
+		// The healthcheck may also come from the image, so only one that has been disabled is a problem
+		if target != nil && target.Healthcheck != nil && target.Healthcheck.Disable {
+			v.problem(path, "service %q has its healthcheck disabled", dependency)
+		}
+	default:
+		v.problem(path, "unknown condition %q", d.Condition)
+	}
+}
+
+func (v *validator) volume(path string, volume ServiceVolume) {
+	if volume.Target == "" {
+		v.problem(path, "target is required")
+	}
+
+	switch volume.Type {
+	case VolumeTypeBind:
+		if volume.Source == "" {
+			v.problem(path, "bind mount has no source")
+		}
+	case VolumeTypeVolume:
+		if _, ok := v.project.Volumes[volume.Source]; volume.Source != "" && !ok {
+			v.problem(path, "volume %q is not defined", volume.Source)
+		}
+	case VolumeTypeTmpfs:
+		if volume.Source != "" {
+			v.problem(path, "tmpfs mount can't have a source")
+		}
+	case "":
+		v.problem(path, "type is required")
+	}
+}
@@ -0,0 +1,255 @@
+// Package diff compares two compose projects, e.g. the effective configuration before and after a change.
+//
+// Rather than a text diff of the YAML, it reports what changed for each service, network, volume and secret: the
+// image, each environment variable, port, volume mount and so on.
+//
+//	before, err := c.ConfigProject(nil, &client.GlobalOptions{Files: []string{"compose.old.yaml"}, FilesMerge: client.ListMergeReplace})
+//	after, err := c.ConfigProject(nil)
+//	d := diff.Compare(before, after, &diff.Options{MaskEnvironment: true})
+//	d.Render(os.Stdout)
+//
+// To compare against a git revision, pass the file at that revision as an inline file, e.g. with the output of
+// `git show main:compose.yaml` as `GlobalOptions.InlineFiles`.
+package diff
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/harrim91/docker-compose-go/compose"
+)
+
+// ChangeKind is whether something was added, removed or modified
+type ChangeKind string
+
+const (
+	Added    ChangeKind = "added"
+	Removed  ChangeKind = "removed"
+	Modified ChangeKind = "modified"
+)
+
+// Options controls what the diff includes
+type Options struct {
+	// Replace environment variable and build argument values with Mask, so secrets aren't shown. Changed values are
+	// still reported.
+	MaskEnvironment bool
+}
+
+// Mask replaces environment variable and build argument values when Options.MaskEnvironment is set
+const Mask = "****"
+
+// Change is a change to a single field of a service, network, volume or secret
+type Change struct {
+	Kind ChangeKind
+
+	// The field, e.g. `image`, `environment`, `ports` or `volumes`
+	Field string
+
+	// Identifies the entry for fields with several, e.g. the environment variable name, the target port and
+	// protocol (`80/tcp`) or the mount's target path. Empty for fields with a single value.
+	Key string
+
+	// The value before and after the change. Old is empty when the field was added, and New when it was removed.
+	Old string
+	New string
+}
+
+// Path is the field and key of the change, e.g. `environment.DB_HOST` or `ports[80/tcp]`
+func (c Change) Path() string {
+	if c.Key == "" {
+		return c.Field
+	}
+
+	switch c.Field {
+	case "ports", "volumes", "volumes_from", "links", "profiles", "secrets":
+		return c.Field + "[" + c.Key + "]"
+	default:
+		return c.Field + "." + c.Key
+	}
+}
+
+// Entry is a service, network, volume or secret that was added, removed or modified.
+//
+// Changes lists every field of an added or removed entry, and the fields that changed for a modified one.
+type Entry struct {
+	Name    string
+	Kind    ChangeKind
+	Changes []Change
+}
+
+// Diff is the difference between two projects. Each list only includes entries that changed, sorted by name.
+type Diff struct {
+	Services []Entry
+	Networks []Entry
+	Volumes  []Entry
+	Secrets  []Entry
+}
+
+// Empty reports whether the projects are the same
+func (d *Diff) Empty() bool {
+	return len(d.Services) == 0 && len(d.Networks) == 0 && len(d.Volumes) == 0 && len(d.Secrets) == 0
+}
+
+// Compare returns the difference from the old project to the new one. Either project may be nil, meaning an empty project.
+func Compare(old, new *compose.Project, opts *Options) *Diff {
+	if opts == nil {
+		opts = &Options{}
+	}
+
+	if old == nil {
+		old = &compose.Project{}
+	}
+
+	if new == nil {
+		new = &compose.Project{}
+	}
+
+	d := &Diff{
+		Services: compareAll(old.Services, new.Services, serviceFields),
+		Networks: compareAll(old.Networks, new.Networks, networkFields),
+		Volumes:  compareAll(old.Volumes, new.Volumes, volumeFields),
+		Secrets:  compareAll(old.Secrets, new.Secrets, secretFields),
+	}
+
+	if opts.MaskEnvironment {
+		maskEnvironment(d.Services)
+	}
+
+	return d
+}
+
+// maskEnvironment replaces the environment variable and build argument values in the changes with Mask. The values
+// are compared first, so a changed value is still reported.
+func maskEnvironment(entries []Entry) {
+	for _, entry := range entries {
+		for i, change := range entry.Changes {
+			if change.Field != "environment" && !(change.Field == "build" && strings.HasPrefix(change.Key, "args.")) {
+				continue
+			}
+
+			if change.Kind != Added {
+				entry.Changes[i].Old = Mask
+			}
+
+			if change.Kind != Removed {
+				entry.Changes[i].New = Mask
+			}
+		}
+	}
+}
+
+// CompareJSON returns the difference between two projects in the JSON output of `ComposeClient.Config`
+func CompareJSON(old, new []byte, opts *Options) (*Diff, error) {
+	oldProject, err := compose.ParseJSON(old)
+
+	if err != nil {
+		return nil, err
+	}
+
+	newProject, err := compose.ParseJSON(new)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return Compare(oldProject, newProject, opts), nil
+}
+
+// compareAll compares the entries in each map with the same name
+func compareAll[V any](old, new map[string]V, flatten func(V) fields) []Entry {
+	var entries []Entry
+
+	for _, name := range unionKeys(old, new) {
+		oldValue, inOld := old[name]
+		newValue, inNew := new[name]
+
+		var oldFields, newFields fields
+
+		if inOld {
+			oldFields = flatten(oldValue)
+		}
+
+		if inNew {
+			newFields = flatten(newValue)
+		}
+
+		entry := Entry{Name: name, Kind: Modified, Changes: compareFields(oldFields, newFields)}
+
+		switch {
+		case !inOld:
+			entry.Kind = Added
+		case !inNew:
+			entry.Kind = Removed
+		case len(entry.Changes) == 0:
+			continue
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
+
+// compareFields returns the changes from the old fields to the new ones, sorted by field and key
+func compareFields(old, new fields) []Change {
+	var changes []Change
+
+	keys := make([]fieldKey, 0, len(new))
+
+	for k := range old {
+		keys = append(keys, k)
+	}
+
+	for k := range new {
+		if _, ok := old[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+
+	for _, k := range keys {
+		oldValue, inOld := old[k]
+		newValue, inNew := new[k]
+
+		change := Change{Kind: Modified, Field: k.field, Key: k.key, Old: oldValue, New: newValue}
+
+		switch {
+		case !inOld:
+			change.Kind = Added
+		case !inNew:
+			change.Kind = Removed
+		case oldValue == newValue:
+			continue
+		}
+
+		changes = append(changes, change)
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].Field != changes[j].Field {
+			return changes[i].Field < changes[j].Field
+		}
+
+		return changes[i].Key < changes[j].Key
+	})
+
+	return changes
+}
+
+// unionKeys returns the keys in either map, sorted
+func unionKeys[V any](a, b map[string]V) []string {
+	var keys []string
+
+	for k := range a {
+		keys = append(keys, k)
+	}
+
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,155 @@
+package diff_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/harrim91/docker-compose-go/diff"
+)
+
+const oldConfig = `{
+  "name": "my-app",
+  "services": {
+    "web": {
+      "image": "nginx:1.24",
+      "environment": {"MODE": "prod", "TOKEN": "abc", "OLD": "x"},
+      "ports": [{"target": 80, "published": "8080", "protocol": "tcp"}],
+      "volumes": [{"type": "volume", "source": "data", "target": "/data"}],
+      "depends_on": {"db": {"condition": "service_started", "required": true}}
+    },
+    "db": {"image": "postgres:16"},
+    "cache": {"image": "redis"}
+  },
+  "volumes": {"data": {"name": "my-app_data"}}
+}`
+
+const newConfig = `{
+  "name": "my-app",
+  "services": {
+    "web": {
+      "image": "nginx:1.25",
+      "environment": {"MODE": "prod", "TOKEN": "def", "DEBUG": "1"},
+      "ports": [{"target": 80, "published": 9090, "protocol": "tcp"}],
+      "volumes": [{"type": "volume", "source": "data", "target": "/data", "read_only": true}],
+      "depends_on": {"db": {"condition": "service_healthy", "required": true}}
+    },
+    "db": {"image": "postgres:16"},
+    "worker": {"image": "my/worker", "command": ["run", "--fast"]}
+  },
+  "volumes": {"data": {"name": "my-app_data", "driver": "nfs"}}
+}`
+
+func compare(t *testing.T, opts *diff.Options) *diff.Diff {
+	t.Helper()
+
+	d, err := diff.CompareJSON([]byte(oldConfig), []byte(newConfig), opts)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return d
+}
+
+func TestCompare(t *testing.T) {
+	d := compare(t, nil)
+
+	expected := []diff.Entry{
+		{Name: "cache", Kind: diff.Removed, Changes: []diff.Change{
+			{Kind: diff.Removed, Field: "image", Old: "redis"},
+		}},
+		{Name: "web", Kind: diff.Modified, Changes: []diff.Change{
+			{Kind: diff.Modified, Field: "depends_on", Key: "db", Old: "service_started", New: "service_healthy"},
+			{Kind: diff.Added, Field: "environment", Key: "DEBUG", New: "1"},
+			{Kind: diff.Removed, Field: "environment", Key: "OLD", Old: "x"},
+			{Kind: diff.Modified, Field: "environment", Key: "TOKEN", Old: "abc", New: "def"},
+			{Kind: diff.Modified, Field: "image", Old: "nginx:1.24", New: "nginx:1.25"},
+			{Kind: diff.Modified, Field: "ports", Key: "80/tcp", Old: "8080", New: "9090"},
+			{Kind: diff.Modified, Field: "volumes", Key: "/data", Old: "volume data", New: "volume data (ro)"},
+		}},
+		{Name: "worker", Kind: diff.Added, Changes: []diff.Change{
+			{Kind: diff.Added, Field: "command", New: "run --fast"},
+			{Kind: diff.Added, Field: "image", New: "my/worker"},
+		}},
+	}
+
+	if !reflect.DeepEqual(d.Services, expected) {
+		t.Errorf("expected %+v, got %+v", expected, d.Services)
+	}
+
+	expectedVolumes := []diff.Entry{
+		{Name: "data", Kind: diff.Modified, Changes: []diff.Change{
+			{Kind: diff.Added, Field: "driver", New: "nfs"},
+		}},
+	}
+
+	if !reflect.DeepEqual(d.Volumes, expectedVolumes) {
+		t.Errorf("expected %+v, got %+v", expectedVolumes, d.Volumes)
+	}
+
+	if d.Networks != nil || d.Secrets != nil || d.Empty() {
+		t.Errorf("unexpected diff: %+v", d)
+	}
+}
+
+func TestCompareSame(t *testing.T) {
+	d, err := diff.CompareJSON([]byte(oldConfig), []byte(oldConfig), nil)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !d.Empty() {
+		t.Errorf("expected no changes, got %+v", d)
+	}
+
+	if d.String() != "No changes.\n" {
+		t.Errorf("unexpected rendering: %q", d.String())
+	}
+}
+
+func TestRenderMasked(t *testing.T) {
+	d := compare(t, &diff.Options{MaskEnvironment: true})
+
+	expected := `- service cache
+~ service web
+    ~ depends_on.db: service_started -> service_healthy
+    + environment.DEBUG: ****
+    - environment.OLD: ****
+    ~ environment.TOKEN: **** -> ****
+    ~ image: nginx:1.24 -> nginx:1.25
+    ~ ports[80/tcp]: 8080 -> 9090
+    ~ volumes[/data]: volume data -> volume data (ro)
++ service worker
+    + command: run --fast
+    + image: my/worker
+~ volume data
+    + driver: nfs
+`
+
+	if d.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, d.String())
+	}
+}
+
+func TestRenderMaskedBuildArgs(t *testing.T) {
+	old := `{"name":"my-app","services":{"web":{"build":{"context":".","args":{"NPM_TOKEN":"abc","OLD":"x"}}}}}`
+	new := `{"name":"my-app","services":{"web":{"build":{"context":"./web","args":{"NPM_TOKEN":"def","DEBUG":"1"}}}}}`
+
+	d, err := diff.CompareJSON([]byte(old), []byte(new), &diff.Options{MaskEnvironment: true})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `~ service web
+    + build.args.DEBUG: ****
+    ~ build.args.NPM_TOKEN: **** -> ****
+    - build.args.OLD: ****
+    ~ build.context: . -> ./web
+`
+
+	if d.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, d.String())
+	}
+}
@@ -0,0 +1,327 @@
+package diff
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/harrim91/docker-compose-go/compose"
+)
+
+type fieldKey struct {
+	field string
+	key   string
+}
+
+// fields flattens a service, network, volume or secret into comparable string values
+type fields map[fieldKey]string
+
+func (f fields) set(field, key, value string) {
+	f[fieldKey{field, key}] = value
+}
+
+// setNonEmpty sets the value, unless it's empty
+func (f fields) setNonEmpty(field, key, value string) {
+	if value != "" {
+		f.set(field, key, value)
+	}
+}
+
+// setList sets the list as a single value, unless it's empty. Lists where the order matters are compared as a whole.
+func (f fields) setList(field, key string, list []string) {
+	if len(list) > 0 {
+		f.set(field, key, strings.Join(list, " "))
+	}
+}
+
+func (f fields) setMap(field string, m map[string]string) {
+	for key, value := range m {
+		f.set(field, key, value)
+	}
+}
+
+func (f fields) setNullableMap(field string, m map[string]*string) {
+	for key, value := range m {
+		f.set(field, key, stringValue(value))
+	}
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+
+	return *s
+}
+
+func serviceFields(s *compose.Service) fields {
+	f := fields{}
+
+	if s == nil {
+		return f
+	}
+
+	f.setNonEmpty("image", "", s.Image)
+	f.setNonEmpty("container_name", "", s.ContainerName)
+	f.setList("command", "", s.Command)
+	f.setList("entrypoint", "", s.Entrypoint)
+	f.setNullableMap("environment", s.Environment)
+	f.setNonEmpty("network_mode", "", s.NetworkMode)
+	f.setNonEmpty("restart", "", s.Restart)
+	f.setNonEmpty("cpus", "", s.CPUs)
+	f.setNonEmpty("mem_limit", "", s.MemLimit)
+	f.setMap("labels", s.Labels)
+
+	if s.Privileged {
+		f.set("privileged", "", "true")
+	}
+
+	if s.Build != nil {
+		f.setNonEmpty("build", "context", s.Build.Context)
+		f.setNonEmpty("build", "dockerfile", s.Build.Dockerfile)
+		f.setNonEmpty("build", "target", s.Build.Target)
+
+		for key, value := range s.Build.Args {
+			f.set("build", "args."+key, stringValue(value))
+		}
+	}
+
+	// Ports are identified by the container port, so a changed host port shows as the port being remapped
+	ports := map[string][]string{}
+
+	for _, port := range s.Ports {
+		protocol := port.Protocol
+
+		if protocol == "" {
+			protocol = "tcp"
+		}
+
+		key := fmt.Sprintf("%d/%s", port.Target, protocol)
+		ports[key] = append(ports[key], publishedPort(port))
+	}
+
+	for key, published := range ports {
+		sort.Strings(published)
+		f.set("ports", key, strings.Join(published, ", "))
+	}
+
+	for _, volume := range s.Volumes {
+		f.set("volumes", volume.Target, mount(volume))
+	}
+
+	for _, from := range s.VolumesFrom {
+		f.set("volumes_from", from, "")
+	}
+
+	for _, link := range s.Links {
+		f.set("links", link, "")
+	}
+
+	for _, profile := range s.Profiles {
+		f.set("profiles", profile, "")
+	}
+
+	for name, network := range s.Networks {
+		f.set("networks", name, serviceNetwork(network))
+	}
+
+	for name, dependency := range s.DependsOn {
+		f.set("depends_on", name, dependsOn(dependency))
+	}
+
+	for _, secret := range s.Secrets {
+		f.set("secrets", secret.Source, secret.Target)
+	}
+
+	if h := s.Healthcheck; h != nil {
+		if h.Disable {
+			f.set("healthcheck", "disable", "true")
+		}
+
+		f.setList("healthcheck", "test", h.Test)
+		f.setNonEmpty("healthcheck", "interval", h.Interval)
+		f.setNonEmpty("healthcheck", "timeout", h.Timeout)
+		f.setNonEmpty("healthcheck", "start_period", h.StartPeriod)
+
+		if h.Retries != nil {
+			f.set("healthcheck", "retries", fmt.Sprint(*h.Retries))
+		}
+	}
+
+	if d := s.Deploy; d != nil {
+		if d.Replicas != nil {
+			f.set("deploy", "replicas", fmt.Sprint(*d.Replicas))
+		}
+
+		if d.Resources != nil {
+			resourceFields(f, "resources.limits", d.Resources.Limits)
+			resourceFields(f, "resources.reservations", d.Resources.Reservations)
+		}
+	}
+
+	return f
+}
+
+func resourceFields(f fields, key string, r *compose.Resource) {
+	if r != nil {
+		f.setNonEmpty("deploy", key+".cpus", r.CPUs)
+		f.setNonEmpty("deploy", key+".memory", r.Memory)
+	}
+}
+
+// publishedPort describes where the port is published, e.g. `127.0.0.1:8080`, or `unpublished`
+func publishedPort(port compose.Port) string {
+	published := port.Published
+
+	if published == "" {
+		published = "unpublished"
+	}
+
+	if port.HostIP != "" {
+		published = port.HostIP + ":" + published
+	}
+
+	if port.Mode != "" && port.Mode != "ingress" {
+		published = fmt.Sprintf("%s (%s)", published, port.Mode)
+	}
+
+	return published
+}
+
+// mount describes a volume mount, e.g. `volume db-data (ro)`
+func mount(v compose.ServiceVolume) string {
+	description := strings.TrimSpace(v.Type + " " + v.Source)
+
+	var options []string
+
+	if v.ReadOnly {
+		options = append(options, "ro")
+	}
+
+	if v.Tmpfs != nil && v.Tmpfs.Size != "" {
+		options = append(options, "size="+v.Tmpfs.Size)
+	}
+
+	if v.Volume != nil && v.Volume.NoCopy {
+		options = append(options, "nocopy")
+	}
+
+	if len(options) > 0 {
+		description = fmt.Sprintf("%s (%s)", description, strings.Join(options, ", "))
+	}
+
+	return description
+}
+
+// serviceNetwork describes how the service is attached to a network, e.g. `aliases=app,web`
+func serviceNetwork(n *compose.ServiceNetwork) string {
+	if n == nil {
+		return ""
+	}
+
+	var settings []string
+
+	if len(n.Aliases) > 0 {
+		aliases := append([]string(nil), n.Aliases...)
+		sort.Strings(aliases)
+		settings = append(settings, "aliases="+strings.Join(aliases, ","))
+	}
+
+	if n.IPv4Address != "" {
+		settings = append(settings, "ipv4_address="+n.IPv4Address)
+	}
+
+	if n.IPv6Address != "" {
+		settings = append(settings, "ipv6_address="+n.IPv6Address)
+	}
+
+	return strings.Join(settings, " ")
+}
+
+// dependsOn describes a dependency, e.g. `service_healthy (optional)`
+func dependsOn(d *compose.Dependency) string {
+	if d == nil {
+		return compose.ConditionServiceStarted
+	}
+
+	description := d.Condition
+
+	if description == "" {
+		description = compose.ConditionServiceStarted
+	}
+
+	var options []string
+
+	if d.Required != nil && !*d.Required {
+		options = append(options, "optional")
+	}
+
+	if d.Restart {
+		options = append(options, "restart")
+	}
+
+	if len(options) > 0 {
+		description = fmt.Sprintf("%s (%s)", description, strings.Join(options, ", "))
+	}
+
+	return description
+}
+
+func networkFields(n *compose.Network) fields {
+	f := fields{}
+
+	if n == nil {
+		return f
+	}
+
+	f.setNonEmpty("name", "", n.Name)
+	f.setNonEmpty("driver", "", n.Driver)
+	f.setMap("labels", n.Labels)
+
+	if n.External {
+		f.set("external", "", "true")
+	}
+
+	if n.Internal {
+		f.set("internal", "", "true")
+	}
+
+	return f
+}
+
+func volumeFields(v *compose.Volume) fields {
+	f := fields{}
+
+	if v == nil {
+		return f
+	}
+
+	f.setNonEmpty("name", "", v.Name)
+	f.setNonEmpty("driver", "", v.Driver)
+	f.setMap("driver_opts", v.DriverOpts)
+	f.setMap("labels", v.Labels)
+
+	if v.External {
+		f.set("external", "", "true")
+	}
+
+	return f
+}
+
+func secretFields(s *compose.Secret) fields {
+	f := fields{}
+
+	if s == nil {
+		return f
+	}
+
+	f.setNonEmpty("name", "", s.Name)
+	f.setNonEmpty("file", "", s.File)
+	f.setNonEmpty("environment", "", s.Environment)
+
+	if s.External {
+		f.set("external", "", "true")
+	}
+
+	return f
+}
@@ -0,0 +1,98 @@
+package diff
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+var symbols = map[ChangeKind]string{
+	Added:    "+",
+	Removed:  "-",
+	Modified: "~",
+}
+
+// Render writes the diff in a human-readable form, e.g.
+//
+//	~ service web
+//	    ~ image: nginx:1.24 -> nginx:1.25
+//	    + environment.DEBUG: 1
+//	    ~ ports[80/tcp]: 8080 -> 9090
+//	+ service worker
+//	    + image: my/worker
+//	- volume cache
+//
+// The fields of removed entries aren't listed.
+func (d *Diff) Render(w io.Writer) error {
+	if d.Empty() {
+		_, err := fmt.Fprintln(w, "No changes.")
+		return err
+	}
+
+	sections := []struct {
+		name    string
+		entries []Entry
+	}{
+		{"service", d.Services},
+		{"network", d.Networks},
+		{"volume", d.Volumes},
+		{"secret", d.Secrets},
+	}
+
+	for _, section := range sections {
+		for _, entry := range section.entries {
+			if _, err := fmt.Fprintf(w, "%s %s %s\n", symbols[entry.Kind], section.name, entry.Name); err != nil {
+				return err
+			}
+
+			if entry.Kind == Removed {
+				continue
+			}
+
+			for _, change := range entry.Changes {
+				if _, err := fmt.Fprintf(w, "    %s %s\n", symbols[change.Kind], describe(change)); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// String returns the rendered diff
+func (d *Diff) String() string {
+	var b bytes.Buffer
+
+	d.Render(&b)
+
+	return b.String()
+}
+
+// describe returns the path and value of the change, e.g. `image: nginx:1.24 -> nginx:1.25`
+func describe(c Change) string {
+	switch c.Kind {
+	case Added:
+		return withValue(c.Path(), c.New)
+	case Removed:
+		return withValue(c.Path(), c.Old)
+	default:
+		return fmt.Sprintf("%s: %s -> %s", c.Path(), quoteEmpty(c.Old), quoteEmpty(c.New))
+	}
+}
+
+func withValue(path, value string) string {
+	if value == "" {
+		return path
+	}
+
+	return fmt.Sprintf("%s: %s", path, value)
+}
+
+func quoteEmpty(value string) string {
+	if value == "" {
+		return `""`
+	}
+
+	return value
+}
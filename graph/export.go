@@ -0,0 +1,71 @@
+package graph
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DOT returns the graph in Graphviz DOT format, with an arrow from each service to the services it depends on.
+// Optional dependencies are dashed.
+//
+//	digraph "my-app" {
+//	  "db";
+//	  "web";
+//	  "web" -> "db" [label="service_healthy"];
+//	}
+func (g *Graph) DOT() string {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "digraph %q {\n", g.name)
+
+	for _, service := range g.services {
+		fmt.Fprintf(&b, "  %q;\n", service)
+	}
+
+	for _, edge := range g.Edges() {
+		style := ""
+
+		if !edge.Required {
+			style = ", style=dashed"
+		}
+
+		fmt.Fprintf(&b, "  %q -> %q [label=%q%s];\n", edge.From, edge.To, edge.label(), style)
+	}
+
+	b.WriteString("}\n")
+
+	return b.String()
+}
+
+// Mermaid returns the graph as a Mermaid flowchart, with an arrow from each service to the services it depends on.
+// Optional dependencies are dotted.
+//
+//	flowchart TD
+//	  s0["db"]
+//	  s1["web"]
+//	  s1 -->|service_healthy| s0
+func (g *Graph) Mermaid() string {
+	var b strings.Builder
+
+	// Service names can contain characters Mermaid doesn't allow in ids, so the services are numbered
+	ids := map[string]string{}
+
+	b.WriteString("flowchart TD\n")
+
+	for i, service := range g.services {
+		ids[service] = fmt.Sprintf("s%d", i)
+		fmt.Fprintf(&b, "  %s[\"%s\"]\n", ids[service], service)
+	}
+
+	for _, edge := range g.Edges() {
+		arrow := "-->"
+
+		if !edge.Required {
+			arrow = "-.->"
+		}
+
+		fmt.Fprintf(&b, "  %s %s|%s| %s\n", ids[edge.From], arrow, edge.label(), ids[edge.To])
+	}
+
+	return b.String()
+}
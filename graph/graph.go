@@ -0,0 +1,404 @@
+// Package graph builds the dependency graph between the services of a compose project.
+//
+// A service depends on another if it refers to it with depends_on, links, `network_mode: service:x` or volumes_from.
+// The graph can be used to order services for startup, find everything up- or downstream of a service, detect
+// cycles, and export to DOT or Mermaid.
+//
+//	project, err := c.ConfigProject(nil)
+//	g := graph.New(project)
+//	order, err := g.Order("web")
+package graph
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/harrim91/docker-compose-go/compose"
+)
+
+// EdgeKind is how one service refers to another
+type EdgeKind string
+
+const (
+	EdgeDependsOn   EdgeKind = "depends_on"
+	EdgeLink        EdgeKind = "links"
+	EdgeNetworkMode EdgeKind = "network_mode"
+	EdgeVolumesFrom EdgeKind = "volumes_from"
+)
+
+// Edge is a dependency of the From service on the To service
+type Edge struct {
+	From string
+	To   string
+	Kind EdgeKind
+
+	// The depends_on condition, e.g. service_healthy. Empty for other kinds.
+	Condition string
+
+	// False if the dependency is a depends_on with `required: false`, so the From service starts without it
+	Required bool
+}
+
+// label describes the edge for export, e.g. `service_healthy` or `links`
+func (e Edge) label() string {
+	if e.Kind == EdgeDependsOn && e.Condition != "" {
+		return e.Condition
+	}
+
+	return string(e.Kind)
+}
+
+// Graph is the dependency graph of a project. It should be created with `New` or `Parse`.
+type Graph struct {
+	name     string
+	services []string
+
+	// Edges from each service to the services it depends on, and from each service to the services that depend on it
+	dependencies map[string][]Edge
+	dependents   map[string][]Edge
+}
+
+// CycleError is returned when services depend on each other. Path starts and ends with the same service, e.g. [a b a].
+type CycleError struct {
+	Path []string
+}
+
+func (e *CycleError) Error() string {
+	return fmt.Sprintf("dependency cycle: %s", strings.Join(e.Path, " -> "))
+}
+
+// New returns the dependency graph of the project.
+//
+// References to services that aren't defined are ignored; `Project.Validate` reports them.
+func New(p *compose.Project) *Graph {
+	g := &Graph{
+		name:         p.Name,
+		services:     p.ServiceNames(),
+		dependencies: map[string][]Edge{},
+		dependents:   map[string][]Edge{},
+	}
+
+	for _, name := range g.services {
+		s := p.Services[name]
+
+		if s == nil {
+			continue
+		}
+
+		var edges []Edge
+
+		for to, dependency := range s.DependsOn {
+			edge := Edge{From: name, To: to, Kind: EdgeDependsOn, Required: true}
+
+			if dependency != nil {
+				edge.Condition = dependency.Condition
+				edge.Required = dependency.Required == nil || *dependency.Required
+			}
+
+			edges = append(edges, edge)
+		}
+
+		for _, link := range s.Links {
+			edges = append(edges, Edge{From: name, To: strings.SplitN(link, ":", 2)[0], Kind: EdgeLink, Required: true})
+		}
+
+		if strings.HasPrefix(s.NetworkMode, "service:") {
+			edges = append(edges, Edge{From: name, To: strings.TrimPrefix(s.NetworkMode, "service:"), Kind: EdgeNetworkMode, Required: true})
+		}
+
+		for _, from := range s.VolumesFrom {
+			if strings.HasPrefix(from, "container:") {
+				continue
+			}
+
+			to := strings.SplitN(strings.TrimPrefix(from, "service:"), ":", 2)[0]
+			edges = append(edges, Edge{From: name, To: to, Kind: EdgeVolumesFrom, Required: true})
+		}
+
+		for _, edge := range edges {
+			if _, ok := p.Services[edge.To]; !ok {
+				continue
+			}
+
+			g.dependencies[name] = append(g.dependencies[name], edge)
+			g.dependents[edge.To] = append(g.dependents[edge.To], edge)
+		}
+	}
+
+	for _, edges := range g.dependencies {
+		sortEdges(edges)
+	}
+
+	for _, edges := range g.dependents {
+		sortEdges(edges)
+	}
+
+	return g
+}
+
+// Parse returns the dependency graph of the project in the JSON output of `ComposeClient.Config`
+func Parse(config []byte) (*Graph, error) {
+	p, err := compose.ParseJSON(config)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return New(p), nil
+}
+
+func sortEdges(edges []Edge) {
+	sort.SliceStable(edges, func(i, j int) bool {
+		if edges[i].From != edges[j].From {
+			return edges[i].From < edges[j].From
+		}
+
+		if edges[i].To != edges[j].To {
+			return edges[i].To < edges[j].To
+		}
+
+		return edges[i].Kind < edges[j].Kind
+	})
+}
+
+// Services returns the names of the services in the graph, sorted
+func (g *Graph) Services() []string {
+	return append([]string(nil), g.services...)
+}
+
+// Edges returns every edge in the graph, sorted by service
+func (g *Graph) Edges() []Edge {
+	var edges []Edge
+
+	for _, service := range g.services {
+		edges = append(edges, g.dependencies[service]...)
+	}
+
+	return edges
+}
+
+// Dependencies returns the services the service refers to directly, sorted
+func (g *Graph) Dependencies(service string) []string {
+	return targets(g.dependencies[service], func(e Edge) string { return e.To })
+}
+
+// Dependents returns the services that refer directly to the service, sorted
+func (g *Graph) Dependents(service string) []string {
+	return targets(g.dependents[service], func(e Edge) string { return e.From })
+}
+
+// targets returns the distinct services at one end of the edges, sorted
+func targets(edges []Edge, end func(Edge) string) []string {
+	seen := map[string]bool{}
+	var services []string
+
+	for _, edge := range edges {
+		if s := end(edge); !seen[s] {
+			seen[s] = true
+			services = append(services, s)
+		}
+	}
+
+	sort.Strings(services)
+
+	return services
+}
+
+// TransitiveDependencies returns every service the services depend on, directly or indirectly, sorted.
+// The services themselves are only included if they depend on each other.
+func (g *Graph) TransitiveDependencies(services ...string) ([]string, error) {
+	return g.reachable(services, g.Dependencies)
+}
+
+// TransitiveDependents returns every service that depends on the services, directly or indirectly, sorted.
+// These are the services affected when any of the services is restarted or removed.
+func (g *Graph) TransitiveDependents(services ...string) ([]string, error) {
+	return g.reachable(services, g.Dependents)
+}
+
+func (g *Graph) reachable(services []string, next func(string) []string) ([]string, error) {
+	if err := g.defined(services); err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	queue := append([]string(nil), services...)
+
+	for len(queue) > 0 {
+		service := queue[0]
+		queue = queue[1:]
+
+		for _, s := range next(service) {
+			if !seen[s] {
+				seen[s] = true
+				queue = append(queue, s)
+			}
+		}
+	}
+
+	result := make([]string, 0, len(seen))
+
+	for s := range seen {
+		result = append(result, s)
+	}
+
+	sort.Strings(result)
+
+	return result, nil
+}
+
+func (g *Graph) defined(services []string) error {
+	for _, service := range services {
+		i := sort.SearchStrings(g.services, service)
+
+		if i == len(g.services) || g.services[i] != service {
+			return fmt.Errorf("service %q is not defined", service)
+		}
+	}
+
+	return nil
+}
+
+// Order returns the services in the order they start, with each service after everything it depends on.
+// Services that don't depend on each other are sorted by name.
+//
+// If services are given, only they and their transitive dependencies are returned, e.g. to check what `Up` with
+// `Services` will start. Returns a *CycleError if the services depend on each other.
+func (g *Graph) Order(services ...string) ([]string, error) {
+	include := g.services
+
+	if len(services) > 0 {
+		dependencies, err := g.TransitiveDependencies(services...)
+
+		if err != nil {
+			return nil, err
+		}
+
+		include = union(services, dependencies)
+	}
+
+	included := map[string]bool{}
+
+	for _, s := range include {
+		included[s] = true
+	}
+
+	// Kahn's algorithm, taking the first ready service by name each time so the order is stable
+	remaining := map[string]int{}
+	var ready []string
+
+	for _, s := range include {
+		for _, dependency := range g.Dependencies(s) {
+			if included[dependency] {
+				remaining[s]++
+			}
+		}
+
+		if remaining[s] == 0 {
+			ready = append(ready, s)
+		}
+	}
+
+	order := make([]string, 0, len(include))
+
+	for len(ready) > 0 {
+		sort.Strings(ready)
+
+		service := ready[0]
+		ready = ready[1:]
+		order = append(order, service)
+
+		for _, dependent := range g.Dependents(service) {
+			if !included[dependent] {
+				continue
+			}
+
+			remaining[dependent]--
+
+			if remaining[dependent] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+	}
+
+	if len(order) < len(include) {
+		return nil, &CycleError{Path: g.cycle(include)}
+	}
+
+	return order, nil
+}
+
+// union returns the distinct services in a and b, sorted
+func union(a, b []string) []string {
+	seen := map[string]bool{}
+	var result []string
+
+	for _, s := range append(append([]string(nil), a...), b...) {
+		if !seen[s] {
+			seen[s] = true
+			result = append(result, s)
+		}
+	}
+
+	sort.Strings(result)
+
+	return result
+}
+
+// Cycle returns the path of the first dependency cycle found, starting and ending with the same service, or nil if
+// there are no cycles
+func (g *Graph) Cycle() []string {
+	return g.cycle(g.services)
+}
+
+// cycle searches depth first from each of the services in turn, returning the path to the first service visited twice
+func (g *Graph) cycle(services []string) []string {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	state := map[string]int{}
+	var path []string
+	var found []string
+
+	var visit func(string) bool
+
+	visit = func(service string) bool {
+		state[service] = visiting
+		path = append(path, service)
+
+		for _, dependency := range g.Dependencies(service) {
+			switch state[dependency] {
+			case visiting:
+				for i, s := range path {
+					if s == dependency {
+						found = append(append([]string(nil), path[i:]...), dependency)
+						break
+					}
+				}
+
+				return true
+			case unvisited:
+				if visit(dependency) {
+					return true
+				}
+			}
+		}
+
+		path = path[:len(path)-1]
+		state[service] = visited
+
+		return false
+	}
+
+	for _, service := range services {
+		if state[service] == unvisited && visit(service) {
+			return found
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,208 @@
+package graph_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/harrim91/docker-compose-go/compose"
+	"github.com/harrim91/docker-compose-go/graph"
+)
+
+const configJSON = `{
+  "name": "my-app",
+  "services": {
+    "proxy": {"image": "nginx", "links": ["web:app"]},
+    "web": {
+      "image": "my/web",
+      "depends_on": {
+        "db": {"condition": "service_healthy", "required": true},
+        "cache": {"condition": "service_started", "required": false}
+      }
+    },
+    "db": {"image": "postgres"},
+    "cache": {"image": "redis"},
+    "vpn": {"image": "vpn"},
+    "worker": {"image": "my/worker", "network_mode": "service:vpn", "volumes_from": ["web:ro", "container:other"]}
+  }
+}`
+
+func parse(t *testing.T) *graph.Graph {
+	t.Helper()
+
+	g, err := graph.Parse([]byte(configJSON))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return g
+}
+
+func TestGraphEdges(t *testing.T) {
+	g := parse(t)
+
+	expected := []graph.Edge{
+		{From: "proxy", To: "web", Kind: graph.EdgeLink, Required: true},
+		{From: "web", To: "cache", Kind: graph.EdgeDependsOn, Condition: compose.ConditionServiceStarted, Required: false},
+		{From: "web", To: "db", Kind: graph.EdgeDependsOn, Condition: compose.ConditionServiceHealthy, Required: true},
+		{From: "worker", To: "vpn", Kind: graph.EdgeNetworkMode, Required: true},
+		{From: "worker", To: "web", Kind: graph.EdgeVolumesFrom, Required: true},
+	}
+
+	if edges := g.Edges(); !reflect.DeepEqual(edges, expected) {
+		t.Errorf("expected %+v, got %+v", expected, edges)
+	}
+
+	if dependents := g.Dependents("web"); !reflect.DeepEqual(dependents, []string{"proxy", "worker"}) {
+		t.Errorf("unexpected dependents: %v", dependents)
+	}
+
+	if dependencies := g.Dependencies("worker"); !reflect.DeepEqual(dependencies, []string{"vpn", "web"}) {
+		t.Errorf("unexpected dependencies: %v", dependencies)
+	}
+}
+
+func TestGraphOrder(t *testing.T) {
+	g := parse(t)
+
+	order, err := g.Order()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"cache", "db", "vpn", "web", "proxy", "worker"}
+
+	if !reflect.DeepEqual(order, expected) {
+		t.Errorf("expected %v, got %v", expected, order)
+	}
+
+	order, err = g.Order("proxy")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected = []string{"cache", "db", "web", "proxy"}
+
+	if !reflect.DeepEqual(order, expected) {
+		t.Errorf("expected %v, got %v", expected, order)
+	}
+
+	if _, err := g.Order("missing"); err == nil {
+		t.Error("expected an error ordering an undefined service")
+	}
+}
+
+func TestGraphTransitive(t *testing.T) {
+	g := parse(t)
+
+	dependencies, err := g.TransitiveDependencies("worker")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := []string{"cache", "db", "vpn", "web"}; !reflect.DeepEqual(dependencies, expected) {
+		t.Errorf("expected %v, got %v", expected, dependencies)
+	}
+
+	dependents, err := g.TransitiveDependents("db")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := []string{"proxy", "web", "worker"}; !reflect.DeepEqual(dependents, expected) {
+		t.Errorf("expected %v, got %v", expected, dependents)
+	}
+
+	if _, err := g.TransitiveDependents("missing"); err == nil {
+		t.Error("expected an error for an undefined service")
+	}
+}
+
+func TestGraphCycle(t *testing.T) {
+	b := compose.NewBuilder("cycle")
+
+	b.Service("a").Image("a").DependsOn("b", "")
+	b.Service("b").Image("b").Link("c")
+	b.Service("c").Image("c").VolumesFrom("a")
+	b.Service("d").Image("d")
+
+	project, err := b.Build()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g := graph.New(project)
+
+	expected := []string{"a", "b", "c", "a"}
+
+	if cycle := g.Cycle(); !reflect.DeepEqual(cycle, expected) {
+		t.Errorf("expected %v, got %v", expected, cycle)
+	}
+
+	_, err = g.Order()
+
+	var cycleErr *graph.CycleError
+
+	if !errors.As(err, &cycleErr) || !reflect.DeepEqual(cycleErr.Path, expected) {
+		t.Fatalf("expected a CycleError, got %v", err)
+	}
+
+	if err.Error() != "dependency cycle: a -> b -> c -> a" {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	if order, err := g.Order("d"); err != nil || !reflect.DeepEqual(order, []string{"d"}) {
+		t.Errorf("expected to order a service outside the cycle, got %v %v", order, err)
+	}
+
+	if cycle := parse(t).Cycle(); cycle != nil {
+		t.Errorf("expected no cycle, got %v", cycle)
+	}
+}
+
+func TestGraphDOT(t *testing.T) {
+	expected := `digraph "my-app" {
+  "cache";
+  "db";
+  "proxy";
+  "vpn";
+  "web";
+  "worker";
+  "proxy" -> "web" [label="links"];
+  "web" -> "cache" [label="service_started", style=dashed];
+  "web" -> "db" [label="service_healthy"];
+  "worker" -> "vpn" [label="network_mode"];
+  "worker" -> "web" [label="volumes_from"];
+}
+`
+
+	if dot := parse(t).DOT(); dot != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, dot)
+	}
+}
+
+func TestGraphMermaid(t *testing.T) {
+	expected := `flowchart TD
+  s0["cache"]
+  s1["db"]
+  s2["proxy"]
+  s3["vpn"]
+  s4["web"]
+  s5["worker"]
+  s2 -->|links| s4
+  s4 -.->|service_started| s0
+  s4 -->|service_healthy| s1
+  s5 -->|network_mode| s3
+  s5 -->|volumes_from| s4
+`
+
+	if mermaid := parse(t).Mermaid(); mermaid != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, mermaid)
+	}
+}
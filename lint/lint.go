@@ -0,0 +1,140 @@
+// Package lint checks a compose project for likely mistakes, before it is ever started.
+//
+// A Linter runs Rules over the project, each of which reports Findings against a service and field. The built-in
+// rules can be combined with project-specific ones by implementing Rule.
+//
+//	project, err := c.ConfigProject(nil)
+//	findings := lint.New(lint.DefaultRules(projectDir)...).Lint(project)
+//	lint.WriteText(os.Stdout, findings)
+package lint
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/harrim91/docker-compose-go/compose"
+)
+
+// Severity is how serious a finding is. The values match SARIF's result levels.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityNote    Severity = "note"
+)
+
+// Finding is a problem found by a rule
+type Finding struct {
+	// The ID of the rule that found the problem. Set by the Linter, so rules don't need to.
+	Rule string
+
+	// The service the problem was found in. Empty for problems with the project as a whole.
+	Service string
+
+	// The path to the field, e.g. `services.web.ports[0]`
+	Path string
+
+	Severity Severity
+	Message  string
+}
+
+// Rule checks a project for a kind of problem
+type Rule interface {
+	// A short, unique identifier, e.g. `latest-tag`
+	ID() string
+
+	// What the rule checks for, in a sentence
+	Description() string
+
+	// Returns a finding for each problem in the project
+	Check(p *compose.Project) []Finding
+}
+
+// Linter runs rules over projects. It should be created with `New`.
+type Linter struct {
+	Rules []Rule
+}
+
+// New returns a Linter that runs the given rules, e.g. `DefaultRules`
+func New(rules ...Rule) *Linter {
+	return &Linter{Rules: rules}
+}
+
+// Lint runs every rule over the project, returning the findings sorted by path. Indexes in the paths are sorted
+// numerically, so `ports[2]` comes before `ports[10]`.
+func (l *Linter) Lint(p *compose.Project) []Finding {
+	var findings []Finding
+
+	for _, rule := range l.Rules {
+		for _, finding := range rule.Check(p) {
+			finding.Rule = rule.ID()
+			findings = append(findings, finding)
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Path != findings[j].Path {
+			return pathLess(findings[i].Path, findings[j].Path)
+		}
+
+		return findings[i].Rule < findings[j].Rule
+	})
+
+	return findings
+}
+
+// pathLess reports whether path a sorts before path b, comparing the indexes in brackets as numbers
+func pathLess(a, b string) bool {
+	for {
+		ai, bi := strings.Index(a, "["), strings.Index(b, "[")
+
+		if ai < 0 || bi < 0 || a[:ai] != b[:bi] {
+			return a < b
+		}
+
+		a, b = a[ai+1:], b[bi+1:]
+
+		ae, be := strings.Index(a, "]"), strings.Index(b, "]")
+
+		if ae < 0 || be < 0 {
+			return a < b
+		}
+
+		an, aErr := strconv.Atoi(a[:ae])
+		bn, bErr := strconv.Atoi(b[:be])
+
+		if aErr == nil && bErr == nil {
+			if an != bn {
+				return an < bn
+			}
+		} else if a[:ae] != b[:be] {
+			return a[:ae] < b[:be]
+		}
+
+		a, b = a[ae+1:], b[be+1:]
+	}
+}
+
+// LintJSON lints the project in the JSON output of `ComposeClient.Config`
+func (l *Linter) LintJSON(config []byte) ([]Finding, error) {
+	p, err := compose.ParseJSON(config)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return l.Lint(p), nil
+}
+
+// HasErrors reports whether any of the findings has SeverityError, e.g. to fail a CI job
+func HasErrors(findings []Finding) bool {
+	for _, finding := range findings {
+		if finding.Severity == SeverityError {
+			return true
+		}
+	}
+
+	return false
+}
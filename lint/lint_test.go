@@ -0,0 +1,153 @@
+package lint_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/harrim91/docker-compose-go/compose"
+	"github.com/harrim91/docker-compose-go/lint"
+)
+
+const configJSON = `{
+  "name": "my-app",
+  "services": {
+    "web": {
+      "image": "registry.example.com:5000/web",
+      "privileged": true,
+      "ports": [
+        {"target": 80, "published": "8080", "protocol": "tcp"},
+        {"target": 9000, "published": "9000-9002", "protocol": "tcp"}
+      ],
+      "volumes": [
+        {"type": "bind", "source": "/srv/my-app/src", "target": "/src"},
+        {"type": "bind", "source": "/etc/ssl", "target": "/ssl"}
+      ],
+      "depends_on": {"db": {"condition": "service_healthy"}, "cache": {"condition": "service_started"}},
+      "deploy": {"resources": {"limits": {"cpus": 1, "memory": "536870912"}}}
+    },
+    "admin": {
+      "image": "my/admin:latest",
+      "ports": [
+        {"target": 80, "published": "8080", "protocol": "udp"},
+        {"target": 81, "published": "9001", "protocol": "tcp"}
+      ],
+      "mem_limit": 268435456
+    },
+    "db": {
+      "image": "postgres:16",
+      "ports": [{"host_ip": "127.0.0.1", "target": 5432, "published": "5432", "protocol": "tcp"}],
+      "cpus": 0.5,
+      "mem_limit": "1g"
+    },
+    "cache": {
+      "image": "redis@sha256:0123456789abcdef",
+      "ports": [{"host_ip": "127.0.0.2", "target": 6379, "published": "5432", "protocol": "tcp"}],
+      "cpus": 0.5,
+      "mem_limit": "1g"
+    }
+  }
+}`
+
+func lintConfig(t *testing.T, rules ...lint.Rule) []lint.Finding {
+	t.Helper()
+
+	findings, err := lint.New(rules...).LintJSON([]byte(configJSON))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return findings
+}
+
+func TestDefaultRules(t *testing.T) {
+	findings := lintConfig(t, lint.DefaultRules("/srv/my-app")...)
+
+	expected := []lint.Finding{
+		{Rule: "resource-limits", Service: "admin", Path: "services.admin.deploy.resources.limits", Severity: lint.SeverityNote, Message: "no CPU limit is set"},
+		{Rule: "latest-tag", Service: "admin", Path: "services.admin.image", Severity: lint.SeverityWarning, Message: `image "my/admin:latest" uses the latest tag`},
+		{Rule: "dependency-healthcheck", Service: "cache", Path: "services.cache.healthcheck", Severity: lint.SeverityNote, Message: "service has no healthcheck, but is depended on by web"},
+		{Rule: "dependency-healthcheck", Service: "db", Path: "services.db.healthcheck", Severity: lint.SeverityWarning, Message: "service has no healthcheck, but must be healthy for web to start"},
+		{Rule: "latest-tag", Service: "web", Path: "services.web.image", Severity: lint.SeverityWarning, Message: `image "registry.example.com:5000/web" has no tag, so uses latest`},
+		{Rule: "host-port-collision", Service: "web", Path: "services.web.ports[1]", Severity: lint.SeverityError, Message: "host port 9001/tcp is also published by admin"},
+		{Rule: "privileged", Service: "web", Path: "services.web.privileged", Severity: lint.SeverityWarning, Message: "container runs privileged, with full access to the host"},
+		{Rule: "bind-mount-outside-project", Service: "web", Path: "services.web.volumes[1]", Severity: lint.SeverityWarning, Message: "bind mount source /etc/ssl is outside the project directory"},
+	}
+
+	if !reflect.DeepEqual(findings, expected) {
+		t.Errorf("expected:\n%+v\ngot:\n%+v", expected, findings)
+	}
+
+	if !lint.HasErrors(findings) {
+		t.Error("expected the findings to have errors")
+	}
+}
+
+func TestCustomRule(t *testing.T) {
+	rule := lint.NewRule("container-name", "Services shouldn't set a container name.", func(p *compose.Project) []lint.Finding {
+		var findings []lint.Finding
+
+		for _, name := range p.ServiceNames() {
+			if p.Services[name].ContainerName != "" {
+				findings = append(findings, lint.Finding{
+					Service:  name,
+					Path:     "services." + name + ".container_name",
+					Severity: lint.SeverityError,
+					Message:  "container name is set",
+				})
+			}
+		}
+
+		return findings
+	})
+
+	b := compose.NewBuilder("custom")
+	b.Service("web").Image("nginx:1.25").Apply(func(s *compose.Service) { s.ContainerName = "web" })
+
+	project, err := b.Build()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	findings := lint.New(rule).Lint(project)
+
+	expected := []lint.Finding{
+		{Rule: "container-name", Service: "web", Path: "services.web.container_name", Severity: lint.SeverityError, Message: "container name is set"},
+	}
+
+	if !reflect.DeepEqual(findings, expected) {
+		t.Errorf("expected %+v, got %+v", expected, findings)
+	}
+}
+
+func TestLintSortsIndexesNumerically(t *testing.T) {
+	rule := lint.NewRule("each-port", "Reports every port.", func(p *compose.Project) []lint.Finding {
+		var findings []lint.Finding
+
+		for _, i := range []int{10, 2, 1} {
+			findings = append(findings, lint.Finding{
+				Service:  "web",
+				Path:     fmt.Sprintf("services.web.ports[%d]", i),
+				Severity: lint.SeverityNote,
+			})
+		}
+
+		return findings
+	})
+
+	findings := lint.New(rule).Lint(&compose.Project{})
+
+	var paths []string
+
+	for _, finding := range findings {
+		paths = append(paths, finding.Path)
+	}
+
+	expected := []string{"services.web.ports[1]", "services.web.ports[2]", "services.web.ports[10]"}
+
+	if !reflect.DeepEqual(paths, expected) {
+		t.Errorf("expected %v, got %v", expected, paths)
+	}
+}
@@ -0,0 +1,162 @@
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// WriteText writes a finding per line, e.g.
+//
+//	services.web.image: warning: image "nginx" has no tag, so uses latest [latest-tag]
+func WriteText(w io.Writer, findings []Finding) error {
+	for _, f := range findings {
+		if _, err := fmt.Fprintf(w, "%s: %s: %s [%s]\n", f.Path, f.Severity, f.Message, f.Rule); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SARIFOptions describes the run in SARIF output
+type SARIFOptions struct {
+	// The compose file the findings are reported against, relative to the repository root (default: compose.yaml)
+	URI string
+
+	// The rules that were run, which are listed with their descriptions. Rules with findings are always listed.
+	Rules []Rule
+}
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string     `json:"id"`
+	ShortDescription *sarifText `json:"shortDescription,omitempty"`
+}
+
+type sarifText struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     Severity        `json:"level"`
+	Message   sarifText       `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation  `json:"physicalLocation"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+// WriteSARIF writes the findings as a SARIF 2.1.0 log, e.g. for GitHub code scanning.
+//
+// The findings are located by the compose file and the path of the field. The resolved config doesn't record line
+// numbers, so there are no regions.
+func WriteSARIF(w io.Writer, findings []Finding, opts *SARIFOptions) error {
+	if opts == nil {
+		opts = &SARIFOptions{}
+	}
+
+	uri := opts.URI
+
+	if uri == "" {
+		uri = "compose.yaml"
+	}
+
+	driver := sarifDriver{
+		Name:           "docker-compose-go",
+		InformationURI: "https://github.com/harrim91/docker-compose-go",
+		Rules:          []sarifRule{},
+	}
+
+	indexes := map[string]int{}
+
+	addRule := func(id, description string) {
+		if _, ok := indexes[id]; ok {
+			return
+		}
+
+		r := sarifRule{ID: id}
+
+		if description != "" {
+			r.ShortDescription = &sarifText{description}
+		}
+
+		indexes[id] = len(driver.Rules)
+		driver.Rules = append(driver.Rules, r)
+	}
+
+	for _, r := range opts.Rules {
+		addRule(r.ID(), r.Description())
+	}
+
+	results := []sarifResult{}
+
+	for _, f := range findings {
+		addRule(f.Rule, "")
+
+		results = append(results, sarifResult{
+			RuleID:    f.Rule,
+			RuleIndex: indexes[f.Rule],
+			Level:     f.Severity,
+			Message:   sarifText{f.Message},
+			Locations: []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: uri},
+				},
+				LogicalLocations: []sarifLogicalLocation{{
+					FullyQualifiedName: f.Path,
+					Kind:               "member",
+				}},
+			}},
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(sarifLog{
+		Version: "2.1.0",
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Runs: []sarifRun{{
+			Tool:    sarifTool{Driver: driver},
+			Results: results,
+		}},
+	})
+}
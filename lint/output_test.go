@@ -0,0 +1,105 @@
+package lint_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/harrim91/docker-compose-go/lint"
+)
+
+var findings = []lint.Finding{
+	{Rule: "latest-tag", Service: "web", Path: "services.web.image", Severity: lint.SeverityWarning, Message: `image "nginx" has no tag, so uses latest`},
+	{Rule: "custom", Service: "db", Path: "services.db.ports[0]", Severity: lint.SeverityError, Message: "port is published"},
+}
+
+func TestWriteText(t *testing.T) {
+	var b bytes.Buffer
+
+	if err := lint.WriteText(&b, findings); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `services.web.image: warning: image "nginx" has no tag, so uses latest [latest-tag]
+services.db.ports[0]: error: port is published [custom]
+`
+
+	if b.String() != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, b.String())
+	}
+}
+
+func TestWriteSARIF(t *testing.T) {
+	var b bytes.Buffer
+
+	err := lint.WriteSARIF(&b, findings, &lint.SARIFOptions{
+		URI:   "deploy/compose.yaml",
+		Rules: []lint.Rule{lint.Privileged(), lint.LatestTag()},
+	})
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var log struct {
+		Version string
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Rules []struct {
+						ID               string
+						ShortDescription *struct{ Text string }
+					}
+				}
+			}
+			Results []struct {
+				RuleID    string
+				RuleIndex int
+				Level     string
+				Message   struct{ Text string }
+				Locations []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct{ URI string }
+					}
+					LogicalLocations []struct{ FullyQualifiedName string }
+				}
+			}
+		}
+	}
+
+	if err := json.Unmarshal(b.Bytes(), &log); err != nil {
+		t.Fatal(err)
+	}
+
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("unexpected log: %s", b.String())
+	}
+
+	rules := log.Runs[0].Tool.Driver.Rules
+
+	if len(rules) != 3 || rules[0].ID != "privileged" || rules[1].ID != "latest-tag" || rules[2].ID != "custom" {
+		t.Errorf("unexpected rules: %+v", rules)
+	}
+
+	if rules[1].ShortDescription == nil || rules[2].ShortDescription != nil {
+		t.Errorf("expected descriptions for the rules that were run only: %+v", rules)
+	}
+
+	results := log.Runs[0].Results
+
+	if len(results) != 2 {
+		t.Fatalf("unexpected results: %+v", results)
+	}
+
+	result := results[1]
+
+	if result.RuleID != "custom" || result.RuleIndex != 2 || result.Level != "error" || result.Message.Text != "port is published" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	location := result.Locations[0]
+
+	if location.PhysicalLocation.ArtifactLocation.URI != "deploy/compose.yaml" || location.LogicalLocations[0].FullyQualifiedName != "services.db.ports[0]" {
+		t.Errorf("unexpected location: %+v", location)
+	}
+}
@@ -0,0 +1,326 @@
+package lint
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/harrim91/docker-compose-go/compose"
+)
+
+// DefaultRules returns every built-in rule. Bind mounts are checked against the project directory, unless it is empty.
+func DefaultRules(projectDir string) []Rule {
+	rules := []Rule{
+		LatestTag(),
+		DependencyHealthcheck(),
+		HostPortCollision(),
+		Privileged(),
+		ResourceLimits(),
+	}
+
+	if projectDir != "" {
+		rules = append(rules, BindMountOutside(projectDir))
+	}
+
+	return rules
+}
+
+type rule struct {
+	id          string
+	description string
+	check       func(p *compose.Project) []Finding
+}
+
+func (r *rule) ID() string                         { return r.id }
+func (r *rule) Description() string                { return r.description }
+func (r *rule) Check(p *compose.Project) []Finding { return r.check(p) }
+
+// NewRule returns a Rule that runs the check function, for project-specific rules that don't need their own type
+func NewRule(id, description string, check func(p *compose.Project) []Finding) Rule {
+	return &rule{id, description, check}
+}
+
+// eachService calls fn with each service, sorted by name
+func eachService(p *compose.Project, fn func(name string, s *compose.Service)) {
+	for _, name := range p.ServiceNames() {
+		if s := p.Services[name]; s != nil {
+			fn(name, s)
+		}
+	}
+}
+
+// LatestTag finds images that use the `latest` tag, or no tag, so can change between deploys. Images pinned to a digest are allowed.
+func LatestTag() Rule {
+	return NewRule("latest-tag", "Images should be pinned to a tag other than latest, or a digest.", func(p *compose.Project) []Finding {
+		var findings []Finding
+
+		eachService(p, func(name string, s *compose.Service) {
+			if s.Image == "" || strings.Contains(s.Image, "@") {
+				return
+			}
+
+			message := ""
+
+			// The tag follows the last colon after the last slash, which may be a registry port otherwise
+			last := s.Image[strings.LastIndex(s.Image, "/")+1:]
+
+			if i := strings.LastIndex(last, ":"); i == -1 {
+				message = fmt.Sprintf("image %q has no tag, so uses latest", s.Image)
+			} else if last[i+1:] == "latest" {
+				message = fmt.Sprintf("image %q uses the latest tag", s.Image)
+			}
+
+			if message != "" {
+				findings = append(findings, Finding{
+					Service:  name,
+					Path:     fmt.Sprintf("services.%s.image", name),
+					Severity: SeverityWarning,
+					Message:  message,
+				})
+			}
+		})
+
+		return findings
+	})
+}
+
+// DependencyHealthcheck finds services that others depend on without a healthcheck.
+//
+// It is a warning if a dependent waits for the service to be healthy, as that relies on the image defining a
+// healthcheck, and a note otherwise.
+func DependencyHealthcheck() Rule {
+	return NewRule("dependency-healthcheck", "Services that others depend on should define a healthcheck.", func(p *compose.Project) []Finding {
+		dependents := map[string][]string{}
+		healthy := map[string]bool{}
+
+		eachService(p, func(name string, s *compose.Service) {
+			for dependency, d := range s.DependsOn {
+				dependents[dependency] = append(dependents[dependency], name)
+
+				if d != nil && d.Condition == compose.ConditionServiceHealthy {
+					healthy[dependency] = true
+				}
+			}
+		})
+
+		var findings []Finding
+
+		eachService(p, func(name string, s *compose.Service) {
+			if len(dependents[name]) == 0 || s.Healthcheck != nil {
+				return
+			}
+
+			sort.Strings(dependents[name])
+
+			finding := Finding{
+				Service:  name,
+				Path:     fmt.Sprintf("services.%s.healthcheck", name),
+				Severity: SeverityNote,
+				Message:  fmt.Sprintf("service has no healthcheck, but is depended on by %s", strings.Join(dependents[name], ", ")),
+			}
+
+			if healthy[name] {
+				finding.Severity = SeverityWarning
+				finding.Message = fmt.Sprintf("service has no healthcheck, but must be healthy for %s to start", strings.Join(dependents[name], ", "))
+			}
+
+			findings = append(findings, finding)
+		})
+
+		return findings
+	})
+}
+
+// binding is a host port published by a service
+type binding struct {
+	service string
+	path    string
+	hostIP  string
+}
+
+// HostPortCollision finds host ports published more than once on the same address, which fails when the second container starts
+func HostPortCollision() Rule {
+	return NewRule("host-port-collision", "Each host port should only be published once.", func(p *compose.Project) []Finding {
+		var keys []string
+		bindings := map[string][]binding{}
+
+		eachService(p, func(name string, s *compose.Service) {
+			for i, port := range s.Ports {
+				protocol := port.Protocol
+
+				if protocol == "" {
+					protocol = "tcp"
+				}
+
+				for _, published := range publishedPorts(port.Published) {
+					key := fmt.Sprintf("%d/%s", published, protocol)
+
+					if _, ok := bindings[key]; !ok {
+						keys = append(keys, key)
+					}
+
+					bindings[key] = append(bindings[key], binding{
+						service: name,
+						path:    fmt.Sprintf("services.%s.ports[%d]", name, i),
+						hostIP:  port.HostIP,
+					})
+				}
+			}
+		})
+
+		var findings []Finding
+
+		for _, key := range keys {
+			for i, b := range bindings[key] {
+				for _, other := range bindings[key][:i] {
+					if !sameAddress(b.hostIP, other.hostIP) {
+						continue
+					}
+
+					findings = append(findings, Finding{
+						Service:  b.service,
+						Path:     b.path,
+						Severity: SeverityError,
+						Message:  fmt.Sprintf("host port %s is also published by %s", key, other.service),
+					})
+
+					break
+				}
+			}
+		}
+
+		return findings
+	})
+}
+
+// publishedPorts returns the host ports of a published port or range, e.g. `8080` or `8000-8010`
+func publishedPorts(published string) []int {
+	start, end, isRange := strings.Cut(published, "-")
+
+	first, err := strconv.Atoi(start)
+
+	if err != nil {
+		return nil
+	}
+
+	last := first
+
+	if isRange {
+		if last, err = strconv.Atoi(end); err != nil || last < first {
+			return nil
+		}
+	}
+
+	ports := make([]int, 0, last-first+1)
+
+	for port := first; port <= last; port++ {
+		ports = append(ports, port)
+	}
+
+	return ports
+}
+
+// sameAddress reports whether ports published on the two host IPs collide. An empty IP publishes on every address.
+func sameAddress(a, b string) bool {
+	all := func(ip string) bool {
+		return ip == "" || ip == "0.0.0.0" || ip == "::"
+	}
+
+	return a == b || all(a) || all(b)
+}
+
+// Privileged finds privileged containers, which have full access to the host
+func Privileged() Rule {
+	return NewRule("privileged", "Containers should not be privileged.", func(p *compose.Project) []Finding {
+		var findings []Finding
+
+		eachService(p, func(name string, s *compose.Service) {
+			if s.Privileged {
+				findings = append(findings, Finding{
+					Service:  name,
+					Path:     fmt.Sprintf("services.%s.privileged", name),
+					Severity: SeverityWarning,
+					Message:  "container runs privileged, with full access to the host",
+				})
+			}
+		})
+
+		return findings
+	})
+}
+
+// BindMountOutside finds bind mounts of host paths outside the project directory. Relative sources are resolved against it.
+func BindMountOutside(projectDir string) Rule {
+	return NewRule("bind-mount-outside-project", "Bind mounts should be within the project directory.", func(p *compose.Project) []Finding {
+		var findings []Finding
+
+		eachService(p, func(name string, s *compose.Service) {
+			for i, volume := range s.Volumes {
+				if volume.Type != compose.VolumeTypeBind || volume.Source == "" {
+					continue
+				}
+
+				source := volume.Source
+
+				if !filepath.IsAbs(source) {
+					source = filepath.Join(projectDir, source)
+				}
+
+				rel, err := filepath.Rel(projectDir, source)
+
+				if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+					continue
+				}
+
+				findings = append(findings, Finding{
+					Service:  name,
+					Path:     fmt.Sprintf("services.%s.volumes[%d]", name, i),
+					Severity: SeverityWarning,
+					Message:  fmt.Sprintf("bind mount source %s is outside the project directory", volume.Source),
+				})
+			}
+		})
+
+		return findings
+	})
+}
+
+// ResourceLimits finds services without a memory or CPU limit, set with `deploy.resources.limits`, `mem_limit` or `cpus`
+func ResourceLimits() Rule {
+	return NewRule("resource-limits", "Services should limit their memory and CPU use.", func(p *compose.Project) []Finding {
+		var findings []Finding
+
+		eachService(p, func(name string, s *compose.Service) {
+			memory := s.MemLimit != ""
+			cpus := s.CPUs != ""
+
+			if s.Deploy != nil && s.Deploy.Resources != nil && s.Deploy.Resources.Limits != nil {
+				memory = memory || s.Deploy.Resources.Limits.Memory != ""
+				cpus = cpus || s.Deploy.Resources.Limits.CPUs != ""
+			}
+
+			var missing []string
+
+			if !memory {
+				missing = append(missing, "memory")
+			}
+
+			if !cpus {
+				missing = append(missing, "CPU")
+			}
+
+			if len(missing) > 0 {
+				findings = append(findings, Finding{
+					Service:  name,
+					Path:     fmt.Sprintf("services.%s.deploy.resources.limits", name),
+					Severity: SeverityNote,
+					Message:  fmt.Sprintf("no %s limit is set", strings.Join(missing, " or ")),
+				})
+			}
+		})
+
+		return findings
+	})
+}